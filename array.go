@@ -0,0 +1,260 @@
+package zaploggerfilter
+
+import (
+	"fmt"
+	"time"
+
+	"go.uber.org/zap/zapcore"
+)
+
+// SensitiveArrayMarshaler 包装zapcore.ArrayMarshaler，在MarshalLogArray过程中对每个元素进行掩码处理
+// 字符串元素应用字段值匹配模式，对象元素按字段名和字段值过滤，反射元素使用SensitiveDataMarshaler处理
+type SensitiveArrayMarshaler struct {
+	Arr    zapcore.ArrayMarshaler
+	Filter *SensitiveDataFilter
+}
+
+// MarshalLogArray 实现zapcore.ArrayMarshaler接口
+func (m *SensitiveArrayMarshaler) MarshalLogArray(enc zapcore.ArrayEncoder) error {
+	if m.Filter == nil {
+		return m.Arr.MarshalLogArray(enc)
+	}
+	return m.Arr.MarshalLogArray(&filteringArrayEncoder{ArrayEncoder: enc, filter: m.Filter})
+}
+
+// filteringArrayEncoder 对追加的元素进行掩码处理的数组编码器
+type filteringArrayEncoder struct {
+	zapcore.ArrayEncoder
+	filter *SensitiveDataFilter
+}
+
+func (e *filteringArrayEncoder) AppendString(v string) {
+	masked, _ := e.filter.maskString(v)
+	e.ArrayEncoder.AppendString(masked)
+}
+
+func (e *filteringArrayEncoder) AppendByteString(v []byte) {
+	if masked, changed := e.filter.maskString(string(v)); changed {
+		e.ArrayEncoder.AppendString(masked)
+		return
+	}
+	e.ArrayEncoder.AppendByteString(v)
+}
+
+func (e *filteringArrayEncoder) AppendArray(v zapcore.ArrayMarshaler) error {
+	return e.ArrayEncoder.AppendArray(&SensitiveArrayMarshaler{Arr: v, Filter: e.filter})
+}
+
+func (e *filteringArrayEncoder) AppendObject(v zapcore.ObjectMarshaler) error {
+	return e.ArrayEncoder.AppendObject(&sensitiveObjectMarshaler{obj: v, filter: e.filter})
+}
+
+func (e *filteringArrayEncoder) AppendReflected(v interface{}) error {
+	return e.ArrayEncoder.AppendReflected(&SensitiveDataMarshaler{Data: v, Filter: e.filter})
+}
+
+// sensitiveObjectMarshaler 包装zapcore.ObjectMarshaler，在MarshalLogObject过程中过滤敏感字段
+type sensitiveObjectMarshaler struct {
+	obj    zapcore.ObjectMarshaler
+	filter *SensitiveDataFilter
+}
+
+// MarshalLogObject 实现zapcore.ObjectMarshaler接口
+func (m *sensitiveObjectMarshaler) MarshalLogObject(enc zapcore.ObjectEncoder) error {
+	return m.obj.MarshalLogObject(&filteringObjectEncoder{ObjectEncoder: enc, filter: m.filter})
+}
+
+// FilteredInlineMarshaler 包装内联的zapcore.ObjectMarshaler（zap.Inline），在MarshalLogObject过程中逐个字段过滤敏感数据
+type FilteredInlineMarshaler struct {
+	Obj    zapcore.ObjectMarshaler
+	Filter *SensitiveDataFilter
+	// namespace 内联字段所属的命名空间
+	namespace string
+}
+
+// MarshalLogObject 实现zapcore.ObjectMarshaler接口
+func (m *FilteredInlineMarshaler) MarshalLogObject(enc zapcore.ObjectEncoder) error {
+	if m.Filter == nil {
+		return m.Obj.MarshalLogObject(enc)
+	}
+	return m.Obj.MarshalLogObject(&filteringObjectEncoder{ObjectEncoder: enc, filter: m.Filter, namespace: m.namespace})
+}
+
+// filteringObjectEncoder 按字段名和字段值过滤的对象编码器
+// 敏感字段名的值（无论类型）都会被替换为掩码字符串
+type filteringObjectEncoder struct {
+	zapcore.ObjectEncoder
+	filter *SensitiveDataFilter
+	// namespace 通过OpenNamespace打开的命名空间路径
+	namespace string
+}
+
+// addMasked 如果key为敏感字段，写入掩码值
+// 返回: 是否已写入掩码值
+func (e *filteringObjectEncoder) addMasked(key string, value interface{}) bool {
+	name, ok := e.filter.sensitiveKey(key, e.namespace)
+	if !ok {
+		return false
+	}
+	e.ObjectEncoder.AddString(key, fmt.Sprint(e.filter.maskValue(name, value)))
+	return true
+}
+
+func (e *filteringObjectEncoder) AddString(key, value string) {
+	if e.addMasked(key, value) {
+		return
+	}
+	masked, _ := e.filter.maskString(value)
+	e.ObjectEncoder.AddString(key, masked)
+}
+
+func (e *filteringObjectEncoder) AddByteString(key string, value []byte) {
+	if e.addMasked(key, string(value)) {
+		return
+	}
+	if masked, changed := e.filter.maskString(string(value)); changed {
+		e.ObjectEncoder.AddString(key, masked)
+		return
+	}
+	e.ObjectEncoder.AddByteString(key, value)
+}
+
+func (e *filteringObjectEncoder) AddBinary(key string, value []byte) {
+	if !e.addMasked(key, nil) {
+		e.ObjectEncoder.AddBinary(key, value)
+	}
+}
+
+func (e *filteringObjectEncoder) AddArray(key string, value zapcore.ArrayMarshaler) error {
+	if e.addMasked(key, nil) {
+		return nil
+	}
+	return e.ObjectEncoder.AddArray(key, &SensitiveArrayMarshaler{Arr: value, Filter: e.filter})
+}
+
+func (e *filteringObjectEncoder) AddObject(key string, value zapcore.ObjectMarshaler) error {
+	if e.addMasked(key, nil) {
+		return nil
+	}
+	return e.ObjectEncoder.AddObject(key, &sensitiveObjectMarshaler{obj: value, filter: e.filter})
+}
+
+func (e *filteringObjectEncoder) AddReflected(key string, value interface{}) error {
+	if e.addMasked(key, nil) {
+		return nil
+	}
+	return e.ObjectEncoder.AddReflected(key, &SensitiveDataMarshaler{Data: value, Filter: e.filter})
+}
+
+func (e *filteringObjectEncoder) OpenNamespace(key string) {
+	e.namespace = joinNamespace(e.namespace, key)
+	e.ObjectEncoder.OpenNamespace(key)
+}
+
+func (e *filteringObjectEncoder) AddBool(key string, value bool) {
+	if !e.addMasked(key, nil) {
+		e.ObjectEncoder.AddBool(key, value)
+	}
+}
+
+func (e *filteringObjectEncoder) AddComplex128(key string, value complex128) {
+	if !e.addMasked(key, nil) {
+		e.ObjectEncoder.AddComplex128(key, value)
+	}
+}
+
+func (e *filteringObjectEncoder) AddComplex64(key string, value complex64) {
+	if !e.addMasked(key, nil) {
+		e.ObjectEncoder.AddComplex64(key, value)
+	}
+}
+
+func (e *filteringObjectEncoder) AddDuration(key string, value time.Duration) {
+	if !e.addMasked(key, nil) {
+		e.ObjectEncoder.AddDuration(key, value)
+	}
+}
+
+func (e *filteringObjectEncoder) AddFloat64(key string, value float64) {
+	if !e.addMasked(key, nil) {
+		e.ObjectEncoder.AddFloat64(key, value)
+	}
+}
+
+func (e *filteringObjectEncoder) AddFloat32(key string, value float32) {
+	if !e.addMasked(key, nil) {
+		e.ObjectEncoder.AddFloat32(key, value)
+	}
+}
+
+func (e *filteringObjectEncoder) AddInt(key string, value int) {
+	if !e.addMasked(key, nil) {
+		e.ObjectEncoder.AddInt(key, value)
+	}
+}
+
+func (e *filteringObjectEncoder) AddInt64(key string, value int64) {
+	if !e.addMasked(key, nil) {
+		e.ObjectEncoder.AddInt64(key, value)
+	}
+}
+
+func (e *filteringObjectEncoder) AddInt32(key string, value int32) {
+	if !e.addMasked(key, nil) {
+		e.ObjectEncoder.AddInt32(key, value)
+	}
+}
+
+func (e *filteringObjectEncoder) AddInt16(key string, value int16) {
+	if !e.addMasked(key, nil) {
+		e.ObjectEncoder.AddInt16(key, value)
+	}
+}
+
+func (e *filteringObjectEncoder) AddInt8(key string, value int8) {
+	if !e.addMasked(key, nil) {
+		e.ObjectEncoder.AddInt8(key, value)
+	}
+}
+
+func (e *filteringObjectEncoder) AddTime(key string, value time.Time) {
+	if !e.addMasked(key, nil) {
+		e.ObjectEncoder.AddTime(key, value)
+	}
+}
+
+func (e *filteringObjectEncoder) AddUint(key string, value uint) {
+	if !e.addMasked(key, nil) {
+		e.ObjectEncoder.AddUint(key, value)
+	}
+}
+
+func (e *filteringObjectEncoder) AddUint64(key string, value uint64) {
+	if !e.addMasked(key, nil) {
+		e.ObjectEncoder.AddUint64(key, value)
+	}
+}
+
+func (e *filteringObjectEncoder) AddUint32(key string, value uint32) {
+	if !e.addMasked(key, nil) {
+		e.ObjectEncoder.AddUint32(key, value)
+	}
+}
+
+func (e *filteringObjectEncoder) AddUint16(key string, value uint16) {
+	if !e.addMasked(key, nil) {
+		e.ObjectEncoder.AddUint16(key, value)
+	}
+}
+
+func (e *filteringObjectEncoder) AddUint8(key string, value uint8) {
+	if !e.addMasked(key, nil) {
+		e.ObjectEncoder.AddUint8(key, value)
+	}
+}
+
+func (e *filteringObjectEncoder) AddUintptr(key string, value uintptr) {
+	if !e.addMasked(key, nil) {
+		e.ObjectEncoder.AddUintptr(key, value)
+	}
+}
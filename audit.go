@@ -0,0 +1,126 @@
+package zaploggerfilter
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+
+	"go.uber.org/zap/buffer"
+	"go.uber.org/zap/zapcore"
+)
+
+// auditMarkerSuffix 审计模式下标记字段的键名后缀
+const auditMarkerSuffix = "_masked"
+
+// DefaultAuditTrailKey 默认的审计记录字段键名
+const DefaultAuditTrailKey = "log_filter_report"
+
+// SetAuditTrail 设置是否在日志条目中追加审计记录字段
+// 开启后SensitiveDataEncoder会在有字段被掩码时追加 zap.Strings(key, 被掩码的字段名)，
+// 带命名空间的字段使用 "namespace.key" 形式的完整路径，便于在不开启调试模式的情况下审计过滤器的行为
+func (f *SensitiveDataFilter) SetAuditTrail(enabled bool) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.auditTrail = enabled
+}
+
+// AuditTrail 获取是否追加审计记录字段
+func (f *SensitiveDataFilter) AuditTrail() bool {
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+
+	return f.auditTrail
+}
+
+// SetAuditTrailKey 设置审计记录字段的键名，为空时恢复为DefaultAuditTrailKey
+func (f *SensitiveDataFilter) SetAuditTrailKey(key string) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.auditTrailKey = key
+}
+
+// AuditTrailKey 获取审计记录字段的键名
+func (f *SensitiveDataFilter) AuditTrailKey() string {
+	_, key := f.auditTrailSettings()
+	return key
+}
+
+// auditTrailSettings 获取是否追加审计记录字段及其键名
+func (f *SensitiveDataFilter) auditTrailSettings() (bool, string) {
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+
+	if f.auditTrailKey == "" {
+		return f.auditTrail, DefaultAuditTrailKey
+	}
+	return f.auditTrail, f.auditTrailKey
+}
+
+// complexValueMasked 判断复杂类型的值在掩码处理后是否发生变化
+func (f *SensitiveDataFilter) complexValueMasked(data interface{}) bool {
+	original, err := json.Marshal(data)
+	if err != nil {
+		return false
+	}
+	masked, err := (&SensitiveDataMarshaler{Data: data, Filter: f}).MarshalJSON()
+	if err != nil {
+		return false
+	}
+	return !bytes.Equal(original, masked)
+}
+
+// MaskedOnlyEncoder 生成脱敏日志流的编码器
+// 与审计模式的SensitiveDataEncoder配合使用：丢弃带有 key+"_masked" 标记的字段及其标记字段，
+// 从而由同一份审计日志得到不包含敏感数据的日志流
+//
+// 组合方式：
+//
+//	encoder := &SensitiveDataEncoder{
+//		Encoder:   &MaskedOnlyEncoder{Encoder: zapcore.NewJSONEncoder(cfg)},
+//		Filter:    filter,
+//		AuditMode: true,
+//	}
+type MaskedOnlyEncoder struct {
+	zapcore.Encoder
+}
+
+// Clone 实现zapcore.Encoder接口，保留外层的字段丢弃逻辑
+func (e *MaskedOnlyEncoder) Clone() zapcore.Encoder {
+	return &MaskedOnlyEncoder{Encoder: e.Encoder.Clone()}
+}
+
+// EncodeEntry 重写编码方法，丢弃被标记为敏感的字段
+func (e *MaskedOnlyEncoder) EncodeEntry(ent zapcore.Entry, fields []zapcore.Field) (*buffer.Buffer, error) {
+	// 收集被标记的字段
+	var marked map[string]bool
+	for _, field := range fields {
+		if field.Type != zapcore.BoolType || field.Integer != 1 {
+			continue
+		}
+		if key, ok := strings.CutSuffix(field.Key, auditMarkerSuffix); ok && key != "" {
+			if marked == nil {
+				marked = make(map[string]bool)
+			}
+			marked[key] = true
+		}
+	}
+
+	if len(marked) == 0 {
+		return e.Encoder.EncodeEntry(ent, fields)
+	}
+
+	filteredFields := make([]zapcore.Field, 0, len(fields))
+	for _, field := range fields {
+		if marked[field.Key] {
+			continue
+		}
+		if key, ok := strings.CutSuffix(field.Key, auditMarkerSuffix); ok && marked[key] && field.Type == zapcore.BoolType {
+			continue
+		}
+		filteredFields = append(filteredFields, field)
+	}
+
+	return e.Encoder.EncodeEntry(ent, filteredFields)
+}
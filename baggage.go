@@ -0,0 +1,29 @@
+package zaploggerfilter
+
+import (
+	"context"
+	"sort"
+
+	"go.opentelemetry.io/otel/baggage"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// ExtractBaggageFields 将ctx中OpenTelemetry Baggage的所有成员转换为字符串字段，按键名排序
+// filter不为nil时，字段会先经过敏感数据过滤器处理（敏感键名的值会被掩码）
+func ExtractBaggageFields(ctx context.Context, filter *SensitiveDataFilter) []zapcore.Field {
+	members := baggage.FromContext(ctx).Members()
+	if len(members) == 0 {
+		return nil
+	}
+
+	sort.Slice(members, func(i, j int) bool {
+		return members[i].Key() < members[j].Key()
+	})
+
+	fields := make([]zapcore.Field, 0, len(members))
+	for _, member := range members {
+		fields = append(fields, zap.String(member.Key(), member.Value()))
+	}
+	return filter.filterFields(fields)
+}
@@ -0,0 +1,142 @@
+package zaploggerfilter
+
+import (
+	"bytes"
+	"fmt"
+	"runtime"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"go.uber.org/zap/zapcore"
+)
+
+// BatchEntry 批量提交的日志条目
+type BatchEntry struct {
+	Level  zapcore.Level
+	Msg    string
+	Fields []zapcore.Field
+	// Time 条目时间，为零值时使用提交时的时间
+	Time time.Time
+}
+
+// LogBatch 向指定目标批量记录日志
+// 每个条目仍会单独经过编码器，敏感数据过滤对每个条目分别生效；
+// 输出到控制台、文件或WriteSyncer的目标在编码所有条目后一次写入底层输出（超过64KB时分多次写入，条目不会被拆分），
+// 批次期间写入同一输出的其他日志也会随批次一起写入。Splunk、NATS和自定义类型的目标逐条写入
+// 条目不会与其他批量写入交错，一次写入失败时返回错误
+func LogBatch(target string, entries []BatchEntry) (err error) {
+	nl, ok := loadLogger(target)
+	if !ok {
+		return fmt.Errorf("%w: %s", ErrLoggerNotFound, target)
+	}
+	lg := nl.logger
+
+	caller := zapcore.NewEntryCaller(runtime.Caller(1))
+	now := time.Now()
+
+	unlock := lockTarget(target)
+	defer unlock()
+
+	if nl.batch != nil {
+		nl.batch.begin()
+		// PanicLevel的条目会在写入后panic，此时仍需写出已缓存的内容并结束批次
+		defer func() {
+			if endErr := nl.batch.end(); endErr != nil && err == nil {
+				err = fmt.Errorf("failed to write batch to %s: %w", target, endErr)
+			}
+		}()
+	}
+
+	for _, entry := range entries {
+		t := entry.Time
+		if t.IsZero() {
+			t = now
+		}
+		writeEntry(lg, zapcore.Entry{
+			LoggerName: lg.Name(),
+			Time:       t,
+			Level:      entry.Level,
+			Message:    entry.Msg,
+			Caller:     caller,
+		}, entry.Fields)
+	}
+	return nil
+}
+
+// maxBatchWriteSize 批量写入时单次写入底层输出的最大字节数，缓存超过该大小时提前写出，
+// 避免单次写入超过日志文件的轮转大小（lumberjack拒绝超过MaxSize的写入）
+const maxBatchWriteSize = 64 << 10
+
+// batchWriteSyncer 支持批量写入的输出，批量写入期间的内容缓存在内存中，结束时一次写入底层输出
+type batchWriteSyncer struct {
+	ws zapcore.WriteSyncer
+	// batchMu 保证同一时间只有一个批量写入，派生的日志记录器共享同一个输出
+	batchMu  sync.Mutex
+	batching atomic.Bool
+	// mu 保护buf，批量写入期间的写入和写出缓存都需持有
+	mu  sync.Mutex
+	buf bytes.Buffer
+}
+
+// newBatchWriteSyncer 创建写入ws的批量写入输出
+func newBatchWriteSyncer(ws zapcore.WriteSyncer) *batchWriteSyncer {
+	return &batchWriteSyncer{ws: ws}
+}
+
+// Write 实现zapcore.WriteSyncer接口，批量写入期间只写入缓存
+func (w *batchWriteSyncer) Write(p []byte) (int, error) {
+	if w.batching.Load() {
+		w.mu.Lock()
+		defer w.mu.Unlock()
+
+		// 获取锁期间批量写入可能已经结束
+		if w.batching.Load() {
+			if w.buf.Len() > 0 && w.buf.Len()+len(p) > maxBatchWriteSize {
+				if err := w.flush(); err != nil {
+					return 0, err
+				}
+			}
+			return w.buf.Write(p)
+		}
+	}
+	return w.ws.Write(p)
+}
+
+// Sync 实现zapcore.WriteSyncer接口，先写出已缓存的内容，保证Fatal等级别的日志在退出前写入
+func (w *batchWriteSyncer) Sync() error {
+	w.mu.Lock()
+	err := w.flush()
+	w.mu.Unlock()
+	if err != nil {
+		return err
+	}
+	return w.ws.Sync()
+}
+
+// begin 开始批量写入，等待其他批量写入结束
+func (w *batchWriteSyncer) begin() {
+	w.batchMu.Lock()
+	w.batching.Store(true)
+}
+
+// end 结束批量写入，将缓存的内容一次写入底层输出
+func (w *batchWriteSyncer) end() error {
+	defer w.batchMu.Unlock()
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	w.batching.Store(false)
+	return w.flush()
+}
+
+// flush 将缓存的内容写入底层输出，调用方需持有mu
+func (w *batchWriteSyncer) flush() error {
+	if w.buf.Len() == 0 {
+		return nil
+	}
+	_, err := w.ws.Write(w.buf.Bytes())
+	w.buf.Reset()
+	return err
+}
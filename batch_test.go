@@ -0,0 +1,103 @@
+package zaploggerfilter
+
+import (
+	"strings"
+	"sync"
+	"testing"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// countingBuffer 统计写入次数的日志输出缓冲区
+type countingBuffer struct {
+	syncBuffer
+	mu     sync.Mutex
+	writes int
+}
+
+// Write 实现io.Writer接口
+func (b *countingBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	b.writes++
+	b.mu.Unlock()
+
+	return b.syncBuffer.Write(p)
+}
+
+// Writes 获取写入次数
+func (b *countingBuffer) Writes() int {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	return b.writes
+}
+
+func TestLogBatchSingleWrite(t *testing.T) {
+	var buf countingBuffer
+	cfg := bufferConfig("batch", nil, "password")
+	cfg.WriteSyncer = &buf
+	initTestLoggers(t, cfg)
+
+	entries := make([]BatchEntry, 100)
+	for i := range entries {
+		entries[i] = BatchEntry{Level: zapcore.InfoLevel, Msg: "entry", Fields: []zapcore.Field{zap.String("password", "secret")}}
+	}
+	if err := LogBatch("batch", entries); err != nil {
+		t.Fatalf("LogBatch() error = %v", err)
+	}
+
+	if got := buf.Writes(); got != 1 {
+		t.Errorf("LogBatch() wrote %d times, want 1", got)
+	}
+	out := buf.String()
+	if got := strings.Count(out, "entry"); got != len(entries) {
+		t.Errorf("LogBatch() wrote %d entries, want %d", got, len(entries))
+	}
+	if strings.Contains(out, "secret") {
+		t.Errorf("LogBatch() did not mask each entry: %s", out)
+	}
+
+	// 批次结束后恢复逐条写入
+	LogTo("batch", zapcore.InfoLevel, "after")
+	if got := buf.Writes(); got != 2 {
+		t.Errorf("write after batch: writes = %d, want 2", got)
+	}
+}
+
+func TestLogBatchConcurrent(t *testing.T) {
+	var buf syncBuffer
+	initTestLoggers(t, bufferConfig("batch", &buf))
+	if err := WithFields("batch", "batch.request", zap.String("request", "1")); err != nil {
+		t.Fatalf("WithFields() error = %v", err)
+	}
+
+	const goroutines, batches = 8, 20
+	entries := []BatchEntry{{Level: zapcore.InfoLevel, Msg: "batched"}, {Level: zapcore.InfoLevel, Msg: "batched"}}
+	var wg sync.WaitGroup
+	for i := 0; i < goroutines; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			target := "batch"
+			if i%2 == 1 {
+				target = "batch.request"
+			}
+			for j := 0; j < batches; j++ {
+				if err := LogBatch(target, entries); err != nil {
+					t.Errorf("LogBatch() error = %v", err)
+				}
+				LogTo(target, zapcore.InfoLevel, "single")
+			}
+		}(i)
+	}
+	wg.Wait()
+
+	out := buf.String()
+	if got, want := strings.Count(out, "batched"), goroutines*batches*len(entries); got != want {
+		t.Errorf("batched entries = %d, want %d", got, want)
+	}
+	if got, want := strings.Count(out, "single"), goroutines*batches; got != want {
+		t.Errorf("single entries = %d, want %d", got, want)
+	}
+}
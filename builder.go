@@ -0,0 +1,192 @@
+package zaploggerfilter
+
+import (
+	"fmt"
+	"sync"
+
+	"go.uber.org/zap/zapcore"
+)
+
+// CoreBuilder 根据配置创建自定义类型的日志记录器核心
+type CoreBuilder func(cfg Config) (zapcore.Core, error)
+
+// EncoderCoreBuilder 根据配置使用给定的编码器创建日志记录器核心，用于发送到远程服务等输出
+// enc为JSON编码器，开启敏感数据过滤时已包装为SensitiveDataEncoder；level为配置的日志级别
+type EncoderCoreBuilder func(cfg Config, enc zapcore.Encoder, level zapcore.LevelEnabler) (zapcore.Core, error)
+
+// coreBuilders 已注册的自定义日志记录器核心，键为ZapCoreType，值为CoreBuilder或EncoderCoreBuilder
+var coreBuilders sync.Map
+
+// RegisterCoreBuilder 注册自定义类型的日志记录器核心，注册后可在Init、AddTargetLogger等配置中使用该类型
+// 内置类型（Console、File等）优先，使用相同类型注册不会生效；重复注册同一类型会覆盖之前的注册
+// 配置开启敏感数据过滤时，自定义核心写入的字段会先经过敏感数据过滤器处理
+// 核心实现io.Closer时，Reset、Reinit替换或空闲过期移除日志记录器后会调用Close
+func RegisterCoreBuilder(t ZapCoreType, b CoreBuilder) {
+	coreBuilders.Store(t, b)
+}
+
+// RegisterEncoderCoreBuilder 注册使用库提供的编码器的日志记录器核心，Splunk和NATS类型由splunksink和natssink包注册
+// 与RegisterCoreBuilder共用注册表，同一类型以最后一次注册为准；关闭行为与RegisterCoreBuilder一致
+func RegisterEncoderCoreBuilder(t ZapCoreType, b EncoderCoreBuilder) {
+	coreBuilders.Store(t, b)
+}
+
+// lookupCoreBuilder 获取已注册的自定义日志记录器核心
+func lookupCoreBuilder(t ZapCoreType) (CoreBuilder, bool) {
+	v, ok := coreBuilders.Load(t)
+	if !ok {
+		return nil, false
+	}
+	b, ok := v.(CoreBuilder)
+	return b, ok
+}
+
+// lookupEncoderCoreBuilder 获取已注册的使用库提供的编码器的日志记录器核心
+func lookupEncoderCoreBuilder(t ZapCoreType) (EncoderCoreBuilder, bool) {
+	v, ok := coreBuilders.Load(t)
+	if !ok {
+		return nil, false
+	}
+	b, ok := v.(EncoderCoreBuilder)
+	return b, ok
+}
+
+// isRegistered 判断类型是否已注册自定义日志记录器核心
+func isRegistered(t ZapCoreType) bool {
+	_, ok := coreBuilders.Load(t)
+	return ok
+}
+
+// sinkPackages 注册内置远程输出类型的子包
+var sinkPackages = map[ZapCoreType]string{
+	Splunk: "github.com/november4bin/zap-logger-filter/splunksink",
+	NATS:   "github.com/november4bin/zap-logger-filter/natssink",
+}
+
+// errUnregisteredSink 内置远程输出类型未注册时的错误，提示需要导入的子包
+func errUnregisteredSink(t ZapCoreType) error {
+	return fmt.Errorf("zap core type %q is not registered, import %s", t, sinkPackages[t])
+}
+
+// DefaultEncoderConfig 返回内置输出类型当前使用的编码器配置，即初始化时通过WithEncoderConfig设置的配置，未设置时为库默认配置
+// 供远程输出等子包在不通过配置直接创建核心时使用
+func DefaultEncoderConfig() zapcore.EncoderConfig {
+	return activeEncoderConfig()
+}
+
+// filteringCore 在写入前过滤字段的日志记录器核心，用于无法替换编码器的自定义核心
+type filteringCore struct {
+	zapcore.Core
+	filter *SensitiveDataFilter
+	// namespace 通过With添加的zap.Namespace打开的命名空间路径
+	namespace string
+}
+
+// With 实现zapcore.Core接口
+func (c *filteringCore) With(fields []zapcore.Field) zapcore.Core {
+	return &filteringCore{
+		Core:      c.Core.With(c.filter.filterFieldsIn(fields, c.namespace)),
+		filter:    c.filter,
+		namespace: fieldsNamespace(c.namespace, fields),
+	}
+}
+
+// Check 实现zapcore.Core接口
+func (c *filteringCore) Check(ent zapcore.Entry, ce *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	if c.Enabled(ent.Level) {
+		return ce.AddCore(ent, c)
+	}
+	return ce
+}
+
+// Write 实现zapcore.Core接口
+func (c *filteringCore) Write(ent zapcore.Entry, fields []zapcore.Field) error {
+	return c.Core.Write(ent, c.filter.filterFieldsIn(fields, c.namespace))
+}
+
+// fieldsNamespace 获取在命名空间namespace中添加fields之后的命名空间路径
+func fieldsNamespace(namespace string, fields []zapcore.Field) string {
+	for _, field := range fields {
+		if field.Type == zapcore.NamespaceType {
+			namespace = joinNamespace(namespace, field.Key)
+		}
+	}
+	return namespace
+}
+
+// encoderCore 使用编码器写入WriteSyncer的日志核心，与zapcore.NewCore创建的日志核心行为一致，
+// 同时保留编码器，使试运行模式可以按实际写入时的编码器（包括敏感数据过滤）编码日志条目
+type encoderCore struct {
+	zapcore.LevelEnabler
+	enc zapcore.Encoder
+	out zapcore.WriteSyncer
+}
+
+// newEncoderCore 创建使用编码器写入WriteSyncer的日志核心
+func newEncoderCore(enc zapcore.Encoder, out zapcore.WriteSyncer, level zapcore.LevelEnabler) *encoderCore {
+	return &encoderCore{LevelEnabler: level, enc: enc, out: out}
+}
+
+// Level 返回日志核心的最低启用级别，供zapcore.LevelOf使用
+func (c *encoderCore) Level() zapcore.Level {
+	return zapcore.LevelOf(c.LevelEnabler)
+}
+
+// With 实现zapcore.Core接口
+func (c *encoderCore) With(fields []zapcore.Field) zapcore.Core {
+	enc := c.enc.Clone()
+	for _, field := range fields {
+		field.AddTo(enc)
+	}
+	return &encoderCore{LevelEnabler: c.LevelEnabler, enc: enc, out: c.out}
+}
+
+// Check 实现zapcore.Core接口
+func (c *encoderCore) Check(ent zapcore.Entry, ce *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	if c.Enabled(ent.Level) {
+		return ce.AddCore(ent, c)
+	}
+	return ce
+}
+
+// Write 实现zapcore.Core接口，Panic和Fatal级别的日志条目写入后立即同步
+func (c *encoderCore) Write(ent zapcore.Entry, fields []zapcore.Field) error {
+	buf, err := c.enc.EncodeEntry(ent, fields)
+	if err != nil {
+		return err
+	}
+	_, err = c.out.Write(buf.Bytes())
+	buf.Free()
+	if err != nil {
+		return err
+	}
+	if ent.Level > zapcore.ErrorLevel {
+		// 进程可能即将退出，忽略同步错误
+		_ = c.out.Sync()
+	}
+	return nil
+}
+
+// Sync 实现zapcore.Core接口
+func (c *encoderCore) Sync() error {
+	return c.out.Sync()
+}
+
+// coreEncoder 返回日志核心写入时使用的编码器，已包含通过With添加的字段
+// 只能识别本库创建的日志核心，无法获取时返回nil
+func coreEncoder(core zapcore.Core) zapcore.Encoder {
+	for {
+		switch c := core.(type) {
+		case *encoderCore:
+			return c.enc
+		case *statsCore:
+			core = c.Core
+		case *cooldownCore:
+			core = c.Core
+		case *levelBoundedCore:
+			core = c.Core
+		default:
+			return nil
+		}
+	}
+}
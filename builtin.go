@@ -0,0 +1,139 @@
+package zaploggerfilter
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// BuiltinPattern 内置的字段值检测模式
+type BuiltinPattern string
+
+const (
+	// BuiltinCreditCard 信用卡号，经Luhn校验后保留前6位和后4位
+	BuiltinCreditCard BuiltinPattern = "credit_card"
+	// BuiltinEmail 邮箱地址，仅保留域名部分
+	// 同时将 email 和 email_address 字段标记为敏感字段
+	BuiltinEmail BuiltinPattern = "email"
+	// BuiltinIPv4 IPv4地址，掩码最后一段
+	BuiltinIPv4 BuiltinPattern = "ipv4"
+	// BuiltinIPv6 IPv6地址，掩码后4组（接口标识部分）
+	BuiltinIPv6 BuiltinPattern = "ipv6"
+	// BuiltinAWSCredentials AWS访问密钥ID和私有访问密钥
+	BuiltinAWSCredentials BuiltinPattern = "aws_credentials"
+	// BuiltinPEM PEM编码的证书和密钥，替换为 [REDACTED-PEM-{类型}]，支持Unix和Windows换行符
+	BuiltinPEM BuiltinPattern = "pem"
+)
+
+var (
+	// creditCardPattern 信用卡号匹配模式，数字间允许单个空格或连字符
+	creditCardPattern = regexp.MustCompile(`\b\d(?:[ -]?\d){12,18}\b`)
+	// emailPattern 邮箱地址匹配模式，支持带引号的本地部分和国际化域名
+	emailPattern = regexp.MustCompile(`(?:"[^"\r\n]+"|[\p{L}\p{N}!#$%&'*+/=?^_{|}~.` + "`" + `-]+)@(?:[\p{L}\p{N}](?:[\p{L}\p{N}-]*[\p{L}\p{N}])?\.)+[\p{L}\p{N}-]{2,}`)
+	// ipv4Pattern IPv4地址匹配模式，支持CIDR格式，是否为有效地址由掩码算法校验
+	ipv4Pattern = regexp.MustCompile(`\b(?:\d{1,3}\.){3}\d{1,3}(?:/\d{1,2})?\b`)
+	// ipv6Pattern IPv6地址候选匹配模式，匹配至少包含两个冒号的完整单词（包括区域、CIDR和内嵌IPv4的形式），
+	// 避免更长单词中的片段被当作地址，是否为有效地址由ipv6CandidateMask校验
+	ipv6Pattern = regexp.MustCompile(`(?i)[0-9a-z%./]*(?::[0-9a-z%./]*){2,}`)
+	// awsAccessKeyPattern AWS访问密钥ID匹配模式
+	awsAccessKeyPattern = regexp.MustCompile(`\bAKIA[0-9A-Z]{16}\b`)
+	// awsSecretKeyPattern AWS私有访问密钥候选匹配模式，是否为密钥由awsSecretKeyMask判断
+	awsSecretKeyPattern = regexp.MustCompile(`[A-Za-z0-9/+]{40,}`)
+	// pemPattern PEM块匹配模式，第一个分组为PEM类型
+	pemPattern = regexp.MustCompile(`(?s)-----BEGIN ([A-Z0-9 ]+)-----.*?-----END [A-Z0-9 ]+-----`)
+)
+
+// builtinPatterns 内置模式对应的规则，调用时已持有过滤器的写锁
+var builtinPatterns = map[BuiltinPattern]func(f *SensitiveDataFilter){
+	BuiltinCreditCard: func(f *SensitiveDataFilter) {
+		f.addValuePattern(&valuePattern{re: creditCardPattern, algorithm: CreditCardMask{BINDigits: 6, TrailDigits: 4}})
+	},
+	BuiltinEmail: func(f *SensitiveDataFilter) {
+		f.addValuePattern(&valuePattern{re: emailPattern, algorithm: EmailMask{}})
+		f.setFieldAlgorithm("email", EmailMask{})
+		f.setFieldAlgorithm("email_address", EmailMask{})
+	},
+	BuiltinIPv4: func(f *SensitiveDataFilter) {
+		f.addValuePattern(&valuePattern{re: ipv4Pattern, algorithm: IPv4Mask{OctetsToMask: 1}})
+	},
+	BuiltinIPv6: func(f *SensitiveDataFilter) {
+		f.addValuePattern(&valuePattern{re: ipv6Pattern, algorithm: ipv6CandidateMask{IPv6Mask{GroupsToMask: 4}}})
+	},
+	BuiltinAWSCredentials: func(f *SensitiveDataFilter) {
+		f.addValuePattern(&valuePattern{re: awsAccessKeyPattern, replacement: "[REDACTED-AWS-KEY]"})
+		f.addValuePattern(&valuePattern{re: awsSecretKeyPattern, algorithm: awsSecretKeyMask{}})
+	},
+	BuiltinPEM: func(f *SensitiveDataFilter) {
+		f.addValuePattern(&valuePattern{re: pemPattern, algorithm: pemMask{}})
+	},
+}
+
+// awsSecretKeyMask AWS私有访问密钥掩码算法
+// 长度恰好为40且同时包含大小写字母的字符串才视为密钥，以避免误伤SHA-1等十六进制摘要
+type awsSecretKeyMask struct{}
+
+// Mask 实现MaskingAlgorithm接口
+func (awsSecretKeyMask) Mask(value string) string {
+	if len(value) != 40 || !strings.ContainsAny(value, "ABCDEFGHIJKLMNOPQRSTUVWXYZ") ||
+		!strings.ContainsAny(value, "abcdefghijklmnopqrstuvwxyz") {
+		return value
+	}
+	return "[REDACTED-AWS-SECRET]"
+}
+
+// ipv6CandidateMask IPv6地址候选的掩码算法，候选不是有效地址时去掉句末的点号或冒号后再尝试
+// 如 "::ffff:1.2.3.4." 中内嵌的IPv4部分会与地址一起被掩码
+type ipv6CandidateMask struct {
+	IPv6Mask
+}
+
+// Mask 实现MaskingAlgorithm接口
+func (m ipv6CandidateMask) Mask(value string) string {
+	if masked := m.IPv6Mask.Mask(value); masked != value {
+		return masked
+	}
+	trimmed := strings.TrimRight(value, ".:")
+	if trimmed == value || trimmed == "" {
+		return value
+	}
+	if masked := m.IPv6Mask.Mask(trimmed); masked != trimmed {
+		return masked + value[len(trimmed):]
+	}
+	return value
+}
+
+// pemMask PEM块掩码算法，将整个PEM块替换为包含其类型的标记
+type pemMask struct{}
+
+// Mask 实现MaskingAlgorithm接口
+func (pemMask) Mask(value string) string {
+	m := pemPattern.FindStringSubmatch(value)
+	if m == nil {
+		return value
+	}
+	return "[REDACTED-PEM-" + m[1] + "]"
+}
+
+// AddBuiltinPattern 添加内置的字段值检测模式
+// 匹配的字段值无论字段名是否敏感都会被掩码处理
+func (f *SensitiveDataFilter) AddBuiltinPattern(p BuiltinPattern) error {
+	apply, ok := builtinPatterns[p]
+	if !ok {
+		return fmt.Errorf("unknown builtin pattern: %s", p)
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	apply(f)
+	return nil
+}
+
+// SetFieldMaskAlgorithm 设置字段的掩码算法，并将该字段标记为敏感字段
+// 字段值为字符串时使用算法处理，其他类型的值仍替换为掩码字符串
+func (f *SensitiveDataFilter) SetFieldMaskAlgorithm(fieldName string, algorithm MaskingAlgorithm) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.setFieldAlgorithm(fieldName, algorithm)
+}
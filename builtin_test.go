@@ -0,0 +1,32 @@
+package zaploggerfilter
+
+import "testing"
+
+func TestBuiltinIPv6(t *testing.T) {
+	f := NewSensitiveDataFilter(nil)
+	if err := f.AddBuiltinPattern(BuiltinIPv6); err != nil {
+		t.Fatalf("AddBuiltinPattern() error = %v", err)
+	}
+
+	tests := []struct {
+		in, want string
+	}{
+		{"client ::ffff:1.2.3.4 connected", "client 0:0:0:0:***:***:***:*** connected"},
+		{"addr 2001:db8::1.", "addr 2001:db8:0:0:***:***:***:***."},
+		{"fe80::1%eth0 up", "fe80:0:0:0:***:***:***:***%eth0 up"},
+		{"net 2001:db8:abcd:12::/64", "net 2001:db8:abcd:12:***:***:***:***/64"},
+		{"[2001:db8::1]:8080", "[2001:db8:0:0:***:***:***:***]:8080"},
+		{"a ::1, ::2", "a 0:0:0:0:***:***:***:***, 0:0:0:0:***:***:***:***"},
+		// 更长单词中的片段不视为地址
+		{"deadbeefcafe::1 x", "deadbeefcafe::1 x"},
+		{"xdead::1", "xdead::1"},
+		{"at 12:30:45 ok", "at 12:30:45 ok"},
+		{"mac aa:bb:cc:dd:ee:ff", "mac aa:bb:cc:dd:ee:ff"},
+		{"Foo::Bar", "Foo::Bar"},
+	}
+	for _, tt := range tests {
+		if got, _ := f.maskString(tt.in); got != tt.want {
+			t.Errorf("maskString(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,92 @@
+package zaploggerfilter
+
+import (
+	"errors"
+	"fmt"
+	"io"
+
+	"go.uber.org/zap/zapcore"
+)
+
+// coreOutput 自定义类型日志记录器核心的底层输出，如连接到远程服务的核心
+type coreOutput struct {
+	core zapcore.Core
+}
+
+// Sync 实现syncer接口
+func (o *coreOutput) Sync() error {
+	return o.core.Sync()
+}
+
+// Close 实现io.Closer接口，核心未实现io.Closer时不做任何操作
+func (o *coreOutput) Close() error {
+	if c, ok := o.core.(io.Closer); ok {
+		return c.Close()
+	}
+	return nil
+}
+
+// closableOutputs 返回输出中由本库创建、需要关闭的日志文件和自定义核心
+// 用户提供的WriteSyncer和标准输出不由本库关闭
+func closableOutputs(syncers []syncer) []io.Closer {
+	var closers []io.Closer
+	for _, s := range syncers {
+		switch s := s.(type) {
+		case *fileSyncer:
+			closers = append(closers, s)
+		case *coreOutput:
+			closers = append(closers, s)
+		}
+	}
+	return closers
+}
+
+// closeOutputs 关闭输出中的日志文件和自定义核心，closed中已有的输出不会重复关闭
+// 日志文件关闭后再次写入时会重新打开，远程连接等则不能再使用，因此只应在不再使用这些输出时调用
+func closeOutputs(syncers []syncer, closed map[io.Closer]bool) error {
+	var errs []error
+	for _, c := range closableOutputs(syncers) {
+		if closed[c] {
+			continue
+		}
+		closed[c] = true
+		if err := c.Close(); err != nil {
+			errs = append(errs, fmt.Errorf("failed to close log output: %w", err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// usesOutputs 判断输出中是否有给定的日志文件或自定义核心
+func usesOutputs(syncers []syncer, outputs map[io.Closer]bool) bool {
+	for _, c := range closableOutputs(syncers) {
+		if outputs[c] {
+			return true
+		}
+	}
+	return false
+}
+
+// outputsInUse 返回全局日志记录器和所有命名日志记录器正在使用的输出
+func outputsInUse() map[io.Closer]bool {
+	inUse := make(map[io.Closer]bool)
+	mark := func(syncers []syncer) {
+		for _, c := range closableOutputs(syncers) {
+			inUse[c] = true
+		}
+	}
+	if syncers := globalSyncers.Load(); syncers != nil {
+		mark(*syncers)
+	}
+	l.Range(func(_, v interface{}) bool {
+		mark(v.(*namedLogger).syncers)
+		return true
+	})
+	return inUse
+}
+
+// closeReplaced 关闭被替换的日志记录器的输出，仍被其他日志记录器（如派生的日志记录器）或全局日志记录器使用的输出保持打开
+// 调用方需持有initMu
+func closeReplaced(replaced []syncer) error {
+	return closeOutputs(replaced, outputsInUse())
+}
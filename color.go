@@ -0,0 +1,80 @@
+package zaploggerfilter
+
+import (
+	"bytes"
+	"os"
+
+	"go.uber.org/zap/buffer"
+	"go.uber.org/zap/zapcore"
+)
+
+// ANSI颜色代码
+const (
+	colorReset   = "\x1b[0m"
+	colorRed     = "\x1b[31m"
+	colorGreen   = "\x1b[32m"
+	colorYellow  = "\x1b[33m"
+	colorMagenta = "\x1b[35m"
+	colorCyan    = "\x1b[36m"
+)
+
+// colorPool 彩色输出使用的缓冲池
+var colorPool = buffer.NewPool()
+
+// colorEncoder 按日志级别为整行日志添加ANSI颜色的编码器，仅用于控制台输出
+type colorEncoder struct {
+	zapcore.Encoder
+	// lineEnding 编码器配置的行尾
+	lineEnding string
+}
+
+// Clone 实现zapcore.Encoder接口
+func (e *colorEncoder) Clone() zapcore.Encoder {
+	return &colorEncoder{Encoder: e.Encoder.Clone(), lineEnding: e.lineEnding}
+}
+
+// EncodeEntry 实现zapcore.Encoder接口
+func (e *colorEncoder) EncodeEntry(ent zapcore.Entry, fields []zapcore.Field) (*buffer.Buffer, error) {
+	buf, err := e.Encoder.EncodeEntry(ent, fields)
+	if err != nil {
+		return nil, err
+	}
+	defer buf.Free()
+
+	line := bytes.TrimSuffix(buf.Bytes(), []byte(e.lineEnding))
+	colored := colorPool.Get()
+	colored.AppendString(levelColor(ent.Level))
+	_, _ = colored.Write(line)
+	colored.AppendString(colorReset)
+	colored.AppendString(e.lineEnding)
+	return colored, nil
+}
+
+// levelColor 获取日志级别对应的颜色
+func levelColor(level zapcore.Level) string {
+	switch level {
+	case zapcore.DebugLevel:
+		return colorCyan
+	case zapcore.InfoLevel:
+		return colorGreen
+	case zapcore.WarnLevel:
+		return colorYellow
+	case zapcore.ErrorLevel:
+		return colorRed
+	default:
+		return colorMagenta
+	}
+}
+
+// colorEnabled 判断是否输出彩色日志
+// 设置了NO_COLOR环境变量或标准输出不是终端时不输出颜色
+func colorEnabled() bool {
+	if _, ok := os.LookupEnv("NO_COLOR"); ok {
+		return false
+	}
+	info, err := os.Stdout.Stat()
+	if err != nil {
+		return false
+	}
+	return info.Mode()&os.ModeCharDevice != 0
+}
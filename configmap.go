@@ -0,0 +1,227 @@
+package zaploggerfilter
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"maps"
+	"math"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// configSetters 配置键到Config字段的设置函数，键为规范化后的名称（见configMapKey）
+var configSetters = map[string]func(cfg *Config, v interface{}) error{
+	"type": func(cfg *Config, v interface{}) (err error) {
+		var s string
+		s, err = mapString(v)
+		cfg.Type = ZapCoreType(s)
+		return err
+	},
+	"name":             func(cfg *Config, v interface{}) (err error) { cfg.Name, err = mapString(v); return },
+	"level":            func(cfg *Config, v interface{}) (err error) { cfg.Level, err = mapString(v); return },
+	"sensitivefilter":  func(cfg *Config, v interface{}) (err error) { cfg.SensitiveFilter, err = mapBool(v); return },
+	"sensitivefields":  func(cfg *Config, v interface{}) (err error) { cfg.SensitiveFields, err = mapStrings(v); return },
+	"path":             func(cfg *Config, v interface{}) (err error) { cfg.Path, err = mapString(v); return },
+	"paths":            func(cfg *Config, v interface{}) (err error) { cfg.Paths, err = mapStrings(v); return },
+	"maxsize":          func(cfg *Config, v interface{}) (err error) { cfg.MaxSize, err = mapInt(v); return },
+	"maxsizebytes":     func(cfg *Config, v interface{}) (err error) { cfg.MaxSizeBytes, err = mapInt64(v); return },
+	"maxage":           func(cfg *Config, v interface{}) (err error) { cfg.MaxAge, err = mapInt(v); return },
+	"maxbackups":       func(cfg *Config, v interface{}) (err error) { cfg.MaxBackups, err = mapInt(v); return },
+	"compress":         func(cfg *Config, v interface{}) (err error) { cfg.Compress, err = mapBool(v); return },
+	"localtime":        func(cfg *Config, v interface{}) (err error) { cfg.LocalTime, err = mapBool(v); return },
+	"filenametemplate": func(cfg *Config, v interface{}) (err error) { cfg.FilenameTemplate, err = mapString(v); return },
+	"timeformat":       func(cfg *Config, v interface{}) (err error) { cfg.TimeFormat, err = mapString(v); return },
+	"coloroutput":      func(cfg *Config, v interface{}) (err error) { cfg.ColorOutput, err = mapBool(v); return },
+	"prettyjson":       func(cfg *Config, v interface{}) (err error) { cfg.PrettyJSON, err = mapBool(v); return },
+	"idletimeout":      func(cfg *Config, v interface{}) (err error) { cfg.IdleTimeout, err = mapDuration(v); return },
+	"minintervalms":    func(cfg *Config, v interface{}) (err error) { cfg.MinIntervalMs, err = mapIntMap(v); return },
+	"hecurl":           func(cfg *Config, v interface{}) (err error) { cfg.HECUrl, err = mapString(v); return },
+	"hectoken":         func(cfg *Config, v interface{}) (err error) { cfg.HECToken, err = mapString(v); return },
+	"splunkindex":      func(cfg *Config, v interface{}) (err error) { cfg.SplunkIndex, err = mapString(v); return },
+	"source":           func(cfg *Config, v interface{}) (err error) { cfg.Source, err = mapString(v); return },
+	"splunkinsecureskipverify": func(cfg *Config, v interface{}) (err error) {
+		cfg.SplunkInsecureSkipVerify, err = mapBool(v)
+		return
+	},
+	"servers":              func(cfg *Config, v interface{}) (err error) { cfg.Servers, err = mapStrings(v); return },
+	"subject":              func(cfg *Config, v interface{}) (err error) { cfg.Subject, err = mapString(v); return },
+	"natsreconnectbufsize": func(cfg *Config, v interface{}) (err error) { cfg.NATSReconnectBufSize, err = mapInt(v); return },
+}
+
+// ConfigFromMap 从map（如Consul、etcd等配置系统或模板引擎提供的配置）中读取日志记录器配置
+// 键名不区分大小写并忽略下划线和连字符，如 "max_size"、"maxSize" 和 "MaxSize" 均对应MaxSize；
+// 值可以是对应类型或可以解析为对应类型的字符串，IdleTimeout可以使用 "30s" 格式的字符串
+// 返回: 配置；无法识别的键及其值，用于兼容更新版本的配置（不视为错误）；值的类型无效时返回错误
+func ConfigFromMap(m map[string]interface{}) (Config, map[string]interface{}, error) {
+	var cfg Config
+	var unknown map[string]interface{}
+	var errs []error
+	for _, key := range slices.Sorted(maps.Keys(m)) {
+		value := m[key]
+		set, ok := configSetters[configMapKey(key)]
+		if !ok {
+			if unknown == nil {
+				unknown = make(map[string]interface{})
+			}
+			unknown[key] = value
+			continue
+		}
+		if err := set(&cfg, value); err != nil {
+			errs = append(errs, fmt.Errorf("invalid value for %q: %w", key, err))
+		}
+	}
+	if len(errs) > 0 {
+		return Config{}, unknown, errors.Join(errs...)
+	}
+	return cfg, unknown, nil
+}
+
+// configMapKey 规范化配置键名：转换为小写并去除下划线和连字符
+func configMapKey(key string) string {
+	return strings.ToLower(strings.NewReplacer("_", "", "-", "").Replace(key))
+}
+
+// mapString 将配置值转换为字符串
+func mapString(v interface{}) (string, error) {
+	s, ok := v.(string)
+	if !ok {
+		return "", fmt.Errorf("expected string, got %T", v)
+	}
+	return s, nil
+}
+
+// mapBool 将配置值转换为布尔值
+func mapBool(v interface{}) (bool, error) {
+	switch b := v.(type) {
+	case bool:
+		return b, nil
+	case string:
+		return strconv.ParseBool(b)
+	default:
+		return false, fmt.Errorf("expected bool, got %T", v)
+	}
+}
+
+// mapInt64 将配置值转换为整数，浮点数必须为整数值
+func mapInt64(v interface{}) (int64, error) {
+	switch n := v.(type) {
+	case int:
+		return int64(n), nil
+	case int8:
+		return int64(n), nil
+	case int16:
+		return int64(n), nil
+	case int32:
+		return int64(n), nil
+	case int64:
+		return n, nil
+	case uint:
+		return mapUint64(uint64(n))
+	case uint8:
+		return int64(n), nil
+	case uint16:
+		return int64(n), nil
+	case uint32:
+		return int64(n), nil
+	case uint64:
+		return mapUint64(n)
+	case float32:
+		return mapFloat64(float64(n))
+	case float64:
+		return mapFloat64(n)
+	case json.Number:
+		return n.Int64()
+	case string:
+		return strconv.ParseInt(n, 10, 64)
+	default:
+		return 0, fmt.Errorf("expected integer, got %T", v)
+	}
+}
+
+// mapUint64 将无符号整数转换为int64，超出范围时返回错误
+func mapUint64(n uint64) (int64, error) {
+	if n > math.MaxInt64 {
+		return 0, fmt.Errorf("integer out of range: %d", n)
+	}
+	return int64(n), nil
+}
+
+// mapFloat64 将整数值的浮点数转换为int64（如JSON解析得到的数字）
+func mapFloat64(n float64) (int64, error) {
+	if n != math.Trunc(n) || n < math.MinInt64 || n >= math.MaxInt64 {
+		return 0, fmt.Errorf("expected integer, got %v", n)
+	}
+	return int64(n), nil
+}
+
+// mapInt 将配置值转换为int
+func mapInt(v interface{}) (int, error) {
+	n, err := mapInt64(v)
+	if err != nil {
+		return 0, err
+	}
+	if n < math.MinInt || n > math.MaxInt {
+		return 0, fmt.Errorf("integer out of range: %d", n)
+	}
+	return int(n), nil
+}
+
+// mapDuration 将配置值转换为时间间隔，字符串按time.ParseDuration解析，整数为纳秒
+func mapDuration(v interface{}) (time.Duration, error) {
+	switch d := v.(type) {
+	case time.Duration:
+		return d, nil
+	case string:
+		return time.ParseDuration(d)
+	default:
+		n, err := mapInt64(v)
+		return time.Duration(n), err
+	}
+}
+
+// mapStrings 将配置值转换为字符串列表
+func mapStrings(v interface{}) ([]string, error) {
+	switch list := v.(type) {
+	case []string:
+		return append([]string(nil), list...), nil
+	case []interface{}:
+		result := make([]string, 0, len(list))
+		for i, item := range list {
+			s, err := mapString(item)
+			if err != nil {
+				return nil, fmt.Errorf("item %d: %w", i, err)
+			}
+			result = append(result, s)
+		}
+		return result, nil
+	default:
+		return nil, fmt.Errorf("expected string list, got %T", v)
+	}
+}
+
+// mapIntMap 将配置值转换为字符串到整数的映射
+func mapIntMap(v interface{}) (map[string]int, error) {
+	switch m := v.(type) {
+	case map[string]int:
+		result := make(map[string]int, len(m))
+		for key, n := range m {
+			result[key] = n
+		}
+		return result, nil
+	case map[string]interface{}:
+		result := make(map[string]int, len(m))
+		for key, item := range m {
+			n, err := mapInt(item)
+			if err != nil {
+				return nil, fmt.Errorf("key %q: %w", key, err)
+			}
+			result[key] = n
+		}
+		return result, nil
+	default:
+		return nil, fmt.Errorf("expected map, got %T", v)
+	}
+}
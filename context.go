@@ -0,0 +1,228 @@
+package zaploggerfilter
+
+import (
+	"context"
+	"runtime"
+	"sort"
+	"sync/atomic"
+
+	"go.opentelemetry.io/otel/trace"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// RequestIDKey 请求ID字段的键名
+const RequestIDKey = "request_id"
+
+// requestIDContextKey context中存储请求ID的键
+type requestIDContextKey struct{}
+
+// loggerContextKey context中存储日志记录器的键
+type loggerContextKey struct{}
+
+// InjectLogger 将日志记录器存储到context中，之后可以通过FromContext获取
+// 多次注入时FromContext返回最近一次注入的日志记录器；lg为nil时返回原context
+func InjectLogger(ctx context.Context, lg *zap.Logger) context.Context {
+	if lg == nil {
+		return ctx
+	}
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	return context.WithValue(ctx, loggerContextKey{}, lg)
+}
+
+// FromContext 获取通过InjectLogger存储在context中的日志记录器，不存在时返回全局日志记录器
+// ctx可以为nil
+func FromContext(ctx context.Context) *zap.Logger {
+	if lg, ok := loggerFromContext(ctx); ok {
+		return lg
+	}
+	return GetGlobalLogger()
+}
+
+// MustFromContext 获取通过InjectLogger存储在context中的日志记录器，不存在时触发panic
+// 用于要求中间件已经注入日志记录器的代码，ctx可以为nil
+func MustFromContext(ctx context.Context) *zap.Logger {
+	lg, ok := loggerFromContext(ctx)
+	if !ok {
+		panic("zaploggerfilter: no logger in context")
+	}
+	return lg
+}
+
+// loggerFromContext 获取context中的日志记录器
+func loggerFromContext(ctx context.Context) (*zap.Logger, bool) {
+	if ctx == nil {
+		return nil, false
+	}
+	lg, ok := ctx.Value(loggerContextKey{}).(*zap.Logger)
+	return lg, ok
+}
+
+// TraceFieldNames OpenTelemetry追踪上下文字段的键名，键名为空时不输出对应字段
+type TraceFieldNames struct {
+	TraceID    string
+	SpanID     string
+	TraceFlags string
+}
+
+// DefaultTraceFieldNames 默认的追踪上下文字段键名，遵循OpenTelemetry日志规范
+var DefaultTraceFieldNames = TraceFieldNames{
+	TraceID:    "trace_id",
+	SpanID:     "span_id",
+	TraceFlags: "trace_flags",
+}
+
+// traceFieldNames 当前使用的追踪上下文字段键名
+var traceFieldNames atomic.Pointer[TraceFieldNames]
+
+// SetTraceFieldNames 设置追踪上下文字段的键名，如DataDog使用的 dd.trace_id，应在Init之前调用
+func SetTraceFieldNames(names TraceFieldNames) {
+	traceFieldNames.Store(&names)
+}
+
+// getTraceFieldNames 获取当前使用的追踪上下文字段键名
+func getTraceFieldNames() TraceFieldNames {
+	if names := traceFieldNames.Load(); names != nil {
+		return *names
+	}
+	return DefaultTraceFieldNames
+}
+
+// BeginRequest 在context中记录请求ID，之后通过LogToCtx记录的日志都会带有 request_id 字段
+func BeginRequest(ctx context.Context, requestID string) context.Context {
+	return context.WithValue(ctx, requestIDContextKey{}, requestID)
+}
+
+// RequestIDFromContext 获取context中的请求ID
+func RequestIDFromContext(ctx context.Context) (string, bool) {
+	if ctx == nil {
+		return "", false
+	}
+	requestID, ok := ctx.Value(requestIDContextKey{}).(string)
+	return requestID, ok
+}
+
+// contextKey 需要从context中读取的值
+type contextKey struct {
+	field string
+	key   interface{}
+}
+
+// contextKeys 当前使用的context键，按字段名排序
+var contextKeys atomic.Pointer[[]contextKey]
+
+// SetContextKeys 设置需要附加到日志的context值，键为日志字段名，值为context中的键
+// 如网关中间件存储的追踪ID和请求ID，应用于WithContext、DebugCtx等函数和LogToCtx；
+// context中不存在的键会被忽略，传入nil时清除设置
+func SetContextKeys(keys map[string]interface{}) {
+	list := make([]contextKey, 0, len(keys))
+	for field, key := range keys {
+		list = append(list, contextKey{field: field, key: key})
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].field < list[j].field })
+	contextKeys.Store(&list)
+}
+
+// contextFields 获取context中需要附加到日志的字段
+func contextFields(ctx context.Context) []zapcore.Field {
+	var fields []zapcore.Field
+	if requestID, ok := RequestIDFromContext(ctx); ok {
+		fields = append(fields, zap.String(RequestIDKey, requestID))
+	}
+	fields = append(fields, traceFields(ctx)...)
+	if keys := contextKeys.Load(); keys != nil && ctx != nil {
+		for _, k := range *keys {
+			if value := ctx.Value(k.key); value != nil {
+				fields = append(fields, zap.Any(k.field, value))
+			}
+		}
+	}
+	return fields
+}
+
+// traceFields 获取ctx中OpenTelemetry span的追踪上下文字段
+// trace_id和span_id为十六进制字符串，trace_flags为整数
+func traceFields(ctx context.Context) []zapcore.Field {
+	if ctx == nil {
+		return nil
+	}
+	sc := trace.SpanContextFromContext(ctx)
+	if !sc.IsValid() {
+		return nil
+	}
+
+	names := getTraceFieldNames()
+	fields := make([]zapcore.Field, 0, 3)
+	if names.TraceID != "" {
+		fields = append(fields, zap.String(names.TraceID, sc.TraceID().String()))
+	}
+	if names.SpanID != "" {
+		fields = append(fields, zap.String(names.SpanID, sc.SpanID().String()))
+	}
+	if names.TraceFlags != "" {
+		fields = append(fields, zap.Int(names.TraceFlags, int(sc.TraceFlags())))
+	}
+	return fields
+}
+
+// LogToCtx 向指定目标记录日志，并附加context中的请求ID和OpenTelemetry追踪上下文字段
+// 返回: 目标日志记录器是否存在，不存在时按SetMissingLoggerPolicy设置的方式处理
+func LogToCtx(ctx context.Context, target string, lvl zapcore.Level, msg string, fields ...zapcore.Field) bool {
+	lg, ok := targetLogger(target)
+	if lg == nil {
+		return false
+	}
+	if extra := contextFields(ctx); len(extra) > 0 {
+		fields = append(extra, fields...)
+	}
+	lg.Log(lvl, msg, fields...)
+	return ok
+}
+
+// WithContext 返回附加了context中请求ID、追踪上下文和SetContextKeys设置的值的全局日志记录器
+// 附加的字段与其他固定字段一样经过敏感数据过滤器处理；ctx中没有需要附加的值时直接返回全局日志记录器
+func WithContext(ctx context.Context) *zap.Logger {
+	lg := GetGlobalLogger()
+	if fields := contextFields(ctx); len(fields) > 0 {
+		return lg.With(fields...)
+	}
+	return lg
+}
+
+// logCtx 使用全局日志记录器记录日志，并附加context中的字段
+// 调用位置记录为DebugCtx等函数的调用方
+func logCtx(ctx context.Context, lvl zapcore.Level, msg string, fields []zapcore.Field) {
+	ce := GetGlobalLogger().Check(lvl, msg)
+	if ce == nil {
+		return
+	}
+	if ce.Caller.Defined {
+		ce.Caller = zapcore.NewEntryCaller(runtime.Caller(2))
+	}
+	if extra := contextFields(ctx); len(extra) > 0 {
+		fields = append(extra, fields...)
+	}
+	ce.Write(fields...)
+}
+
+// DebugCtx 使用全局日志记录器记录调试级别的日志，并附加context中的字段
+func DebugCtx(ctx context.Context, msg string, fields ...zapcore.Field) {
+	logCtx(ctx, zapcore.DebugLevel, msg, fields)
+}
+
+// InfoCtx 使用全局日志记录器记录信息级别的日志，并附加context中的字段
+func InfoCtx(ctx context.Context, msg string, fields ...zapcore.Field) {
+	logCtx(ctx, zapcore.InfoLevel, msg, fields)
+}
+
+// WarnCtx 使用全局日志记录器记录警告级别的日志，并附加context中的字段
+func WarnCtx(ctx context.Context, msg string, fields ...zapcore.Field) {
+	logCtx(ctx, zapcore.WarnLevel, msg, fields)
+}
+
+// ErrorCtx 使用全局日志记录器记录错误级别的日志，并附加context中的字段
+func ErrorCtx(ctx context.Context, msg string, fields ...zapcore.Field) {
+	logCtx(ctx, zapcore.ErrorLevel, msg, fields)
+}
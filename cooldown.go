@@ -0,0 +1,62 @@
+package zaploggerfilter
+
+import (
+	"fmt"
+	"sync/atomic"
+	"time"
+
+	"go.uber.org/zap/zapcore"
+)
+
+// cooldownCore 限制同一级别日志条目最小间隔的日志核心，用于防止日志风暴
+// 间隔内的其他同级别日志条目会被丢弃，与消息内容无关
+type cooldownCore struct {
+	zapcore.Core
+	// intervals 各级别的最小间隔（纳秒）
+	intervals map[zapcore.Level]int64
+	// last 各级别最后一次写入的时间（Unix纳秒），派生的日志核心共享该值
+	last map[zapcore.Level]*atomic.Int64
+}
+
+// newCooldownCore 根据级别名称到最小间隔（毫秒）的映射创建日志核心
+// 间隔不大于0的级别不受限制；没有需要限制的级别时返回原日志核心
+func newCooldownCore(core zapcore.Core, minIntervalMs map[string]int) (zapcore.Core, error) {
+	intervals := make(map[zapcore.Level]int64, len(minIntervalMs))
+	last := make(map[zapcore.Level]*atomic.Int64, len(minIntervalMs))
+	for name, ms := range minIntervalMs {
+		level, err := parseLoggerLevel(name)
+		if err != nil {
+			return nil, fmt.Errorf("invalid min interval level: %w", err)
+		}
+		if ms <= 0 {
+			continue
+		}
+		intervals[level] = int64(time.Duration(ms) * time.Millisecond)
+		last[level] = new(atomic.Int64)
+	}
+	if len(intervals) == 0 {
+		return core, nil
+	}
+	return &cooldownCore{Core: core, intervals: intervals, last: last}, nil
+}
+
+// With 实现zapcore.Core接口
+func (c *cooldownCore) With(fields []zapcore.Field) zapcore.Core {
+	return &cooldownCore{Core: c.Core.With(fields), intervals: c.intervals, last: c.last}
+}
+
+// Check 实现zapcore.Core接口，距离上一条同级别日志条目不足最小间隔时丢弃
+func (c *cooldownCore) Check(ent zapcore.Entry, ce *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	interval, ok := c.intervals[ent.Level]
+	if !ok || !c.Enabled(ent.Level) {
+		return c.Core.Check(ent, ce)
+	}
+
+	last := c.last[ent.Level]
+	now := time.Now().UnixNano()
+	prev := last.Load()
+	if now-prev < interval || !last.CompareAndSwap(prev, now) {
+		return ce
+	}
+	return c.Core.Check(ent, ce)
+}
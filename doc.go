@@ -0,0 +1,23 @@
+// Package zaploggerfilter 基于zap的多目标日志记录器，支持在编码阶段对敏感数据进行掩码处理
+//
+// 通过配置初始化命名日志记录器，全局日志记录器L会合并所有配置的日志记录器：
+//
+//	ok, err := zaploggerfilter.InitOnce([]zaploggerfilter.Config{
+//		{Type: zaploggerfilter.Console, Name: "console", Level: "info"},
+//		{
+//			Type:            zaploggerfilter.File,
+//			Name:            "file",
+//			Level:           "debug",
+//			Path:            "./logs/app.log",
+//			SensitiveFilter: true,
+//			SensitiveFields: []string{"password", "token"},
+//		},
+//	})
+//
+// 之后可以向指定的日志记录器写入日志，敏感字段的值会被替换为掩码：
+//
+//	zaploggerfilter.InfoTo("file", "用户登录", zap.String("password", "secret")) // password: "***"
+//
+// 不使用配置时，可以直接组合SensitiveDataEncoder和日志核心，或使用SensitiveDataFilter处理
+// map、结构体等数据。更多用法见README
+package zaploggerfilter
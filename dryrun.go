@@ -0,0 +1,137 @@
+package zaploggerfilter
+
+import (
+	"errors"
+	"sync"
+
+	"go.uber.org/zap/zapcore"
+)
+
+// DryRunCore 试运行模式的控制器
+// 试运行模式下日志条目只缓存在内存中，不会写入目标位置，
+// 可以通过CapturedEntries查看，通过Flush写入内部日志核心，或通过Discard丢弃
+type DryRunCore struct {
+	inner   zapcore.Core
+	mu      sync.Mutex
+	records []dryRunRecord
+}
+
+// dryRunRecord 缓存的日志条目
+type dryRunRecord struct {
+	// core 条目回放时写入的日志核心（已包含With添加的字段）
+	core zapcore.Core
+	// encoded 记录时编码后的内容，编码失败时为nil
+	encoded []byte
+	ent     zapcore.Entry
+	fields  []zapcore.Field
+}
+
+// NewDryRunCore 创建试运行模式的日志核心
+// 日志条目在记录时使用内部日志核心的编码器编码，内部日志核心由本库创建（如NewFileCore）时，
+// CapturedEntries与实际写入的内容一致，敏感数据同样被掩码；其他日志核心使用JSON编码器，不经过敏感数据过滤
+// 返回: 控制器，以及用于创建日志记录器的日志核心
+func NewDryRunCore(inner zapcore.Core) (*DryRunCore, zapcore.Core) {
+	d := &DryRunCore{inner: inner}
+	enc := coreEncoder(inner)
+	if enc == nil {
+		enc = zapcore.NewJSONEncoder(activeEncoderConfig())
+	}
+	return d, &dryRunCore{controller: d, inner: inner, enc: enc}
+}
+
+// Flush 将缓存的日志条目按记录顺序写入内部日志核心，并清空缓存
+func (d *DryRunCore) Flush() error {
+	d.mu.Lock()
+	records := d.records
+	d.records = nil
+	d.mu.Unlock()
+
+	var errs []error
+	for _, record := range records {
+		if err := record.core.Write(record.ent, record.fields); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	if err := d.inner.Sync(); err != nil {
+		errs = append(errs, err)
+	}
+	return errors.Join(errs...)
+}
+
+// CapturedEntries 返回缓存的日志条目编码后的内容，编码失败的条目不包含在内
+func (d *DryRunCore) CapturedEntries() [][]byte {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	entries := make([][]byte, 0, len(d.records))
+	for _, record := range d.records {
+		if record.encoded != nil {
+			entries = append(entries, record.encoded)
+		}
+	}
+	return entries
+}
+
+// Discard 丢弃所有缓存的日志条目
+func (d *DryRunCore) Discard() {
+	d.mu.Lock()
+	d.records = nil
+	d.mu.Unlock()
+}
+
+// dryRunCore 试运行模式的日志核心，实现zapcore.Core接口
+type dryRunCore struct {
+	controller *DryRunCore
+	inner      zapcore.Core
+	// enc 编码缓存条目的编码器，已包含With添加的字段
+	enc zapcore.Encoder
+}
+
+// Enabled 实现zapcore.LevelEnabler接口，与内部日志核心保持一致
+func (c *dryRunCore) Enabled(lvl zapcore.Level) bool {
+	return c.inner.Enabled(lvl)
+}
+
+// With 实现zapcore.Core接口
+func (c *dryRunCore) With(fields []zapcore.Field) zapcore.Core {
+	inner := c.inner.With(fields)
+	enc := coreEncoder(inner)
+	if enc == nil {
+		enc = c.enc.Clone()
+		for _, field := range fields {
+			field.AddTo(enc)
+		}
+	}
+	return &dryRunCore{controller: c.controller, inner: inner, enc: enc}
+}
+
+// Check 实现zapcore.Core接口
+func (c *dryRunCore) Check(ent zapcore.Entry, ce *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	if c.Enabled(ent.Level) {
+		return ce.AddCore(ent, c)
+	}
+	return ce
+}
+
+// Write 实现zapcore.Core接口，缓存日志条目而不写入
+func (c *dryRunCore) Write(ent zapcore.Entry, fields []zapcore.Field) error {
+	record := dryRunRecord{
+		core:   c.inner,
+		ent:    ent,
+		fields: append([]zapcore.Field(nil), fields...),
+	}
+	if buf, err := c.enc.EncodeEntry(ent, fields); err == nil {
+		record.encoded = append([]byte(nil), buf.Bytes()...)
+		buf.Free()
+	}
+
+	c.controller.mu.Lock()
+	c.controller.records = append(c.controller.records, record)
+	c.controller.mu.Unlock()
+	return nil
+}
+
+// Sync 实现zapcore.Core接口，试运行模式下没有需要刷新的内容
+func (c *dryRunCore) Sync() error {
+	return nil
+}
@@ -0,0 +1,33 @@
+package zaploggerfilter
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"go.uber.org/zap"
+)
+
+func TestDryRunCapturedEntriesMasked(t *testing.T) {
+	filter := NewSensitiveDataFilter([]string{"password", "token"})
+	fileCore, err := NewFileCore("dryrun", filepath.Join(t.TempDir(), "app.log"), "debug", filter)
+	if err != nil {
+		t.Fatalf("NewFileCore() error = %v", err)
+	}
+	dryRun, core := NewDryRunCore(fileCore)
+	logger := zap.New(core).With(zap.String("token", "abc123"))
+
+	logger.Info("login", zap.String("user", "alice"), zap.String("password", "secret"))
+
+	entries := dryRun.CapturedEntries()
+	if len(entries) != 1 {
+		t.Fatalf("CapturedEntries() returned %d entries, want 1", len(entries))
+	}
+	got := string(entries[0])
+	if strings.Contains(got, "secret") || strings.Contains(got, "abc123") {
+		t.Errorf("captured entry = %q, want sensitive fields masked", got)
+	}
+	if !strings.Contains(got, `"user":"alice"`) || !strings.Contains(got, `"password":"***"`) {
+		t.Errorf("captured entry = %q, want the file core's encoding", got)
+	}
+}
@@ -0,0 +1,46 @@
+package zaploggerfilter
+
+import (
+	"time"
+
+	"go.uber.org/zap/zapcore"
+)
+
+// LogEntry 可以稍后或在其他goroutine中写入的日志条目，如通过channel传递给统一写入日志的goroutine
+// 创建后不应再修改Fields中的字段
+type LogEntry struct {
+	// Target 目标日志记录器名称
+	Target string
+	// Level 日志级别
+	Level zapcore.Level
+	// Msg 日志消息
+	Msg string
+	// Fields 日志字段
+	Fields []zapcore.Field
+	// Time 日志时间，为零值时使用写入时的时间
+	Time time.Time
+}
+
+// EmitEntry 将日志条目写入目标日志记录器，经过与LogTo相同的级别检查和敏感数据过滤
+// Fatal和Panic级别的条目只会被写入，不会退出程序或触发panic
+// 返回: 目标日志记录器是否存在，不存在时按SetMissingLoggerPolicy设置的方式处理
+func EmitEntry(entry LogEntry) bool {
+	lg, ok := targetLogger(entry.Target)
+	if lg == nil {
+		return false
+	}
+
+	ent := zapcore.Entry{
+		LoggerName: lg.Name(),
+		Level:      entry.Level,
+		Time:       entry.Time,
+		Message:    entry.Msg,
+	}
+	if ent.Time.IsZero() {
+		ent.Time = time.Now()
+	}
+	if ce := lg.Core().Check(ent, nil); ce != nil {
+		ce.Write(entry.Fields...)
+	}
+	return ok
+}
@@ -0,0 +1,575 @@
+package zaploggerfilter_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	zaploggerfilter "github.com/november4bin/zap-logger-filter"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// exampleEncoderConfig 示例使用的编码器配置，省略时间和调用位置以便比较输出
+var exampleEncoderConfig = zapcore.EncoderConfig{
+	LevelKey:         "level",
+	NameKey:          "logger",
+	MessageKey:       "msg",
+	EncodeLevel:      zapcore.LowercaseLevelEncoder,
+	ConsoleSeparator: " ",
+}
+
+// stdoutConsole 输出到标准输出的控制台日志记录器配置
+func stdoutConsole(name string, sensitiveFields ...string) zaploggerfilter.Config {
+	return zaploggerfilter.Config{
+		Type:            zaploggerfilter.Console,
+		Name:            name,
+		Level:           "debug",
+		SensitiveFilter: len(sensitiveFields) > 0,
+		SensitiveFields: sensitiveFields,
+		WriteSyncer:     zapcore.AddSync(os.Stdout),
+	}
+}
+
+// initExample 使用示例的编码器配置初始化日志记录器
+func initExample(cfg ...zaploggerfilter.Config) {
+	err := zaploggerfilter.InitWithOptions(
+		zaploggerfilter.WithEncoderConfig(exampleEncoderConfig),
+		zaploggerfilter.WithConfigs(cfg),
+	)
+	if err != nil {
+		panic(err)
+	}
+}
+
+// newExampleLogger 创建使用filter过滤敏感数据并以JSON格式输出到标准输出的日志记录器
+func newExampleLogger(filter *zaploggerfilter.SensitiveDataFilter) *zap.Logger {
+	encoder := zaploggerfilter.NewJSONEncoderWithFilter(exampleEncoderConfig, filter)
+	return zap.New(zapcore.NewCore(encoder, zapcore.AddSync(os.Stdout), zapcore.DebugLevel))
+}
+
+func Example() {
+	defer zaploggerfilter.Reset()
+	initExample(stdoutConsole("app", "password", "token"))
+
+	zaploggerfilter.InfoTo("app", "用户登录", zap.String("user", "alice"), zap.String("password", "secret"))
+	// Output:
+	// info 用户登录 {"user": "alice", "password": "***"}
+}
+
+func ExampleInitOnce() {
+	defer zaploggerfilter.Reset()
+
+	cfg := []zaploggerfilter.Config{{Type: zaploggerfilter.Console, Name: "app", Level: "info"}}
+	first, err := zaploggerfilter.InitOnce(cfg)
+	fmt.Println(first, err)
+	second, err := zaploggerfilter.InitOnce(cfg)
+	fmt.Println(second, err)
+	// Output:
+	// true <nil>
+	// false <nil>
+}
+
+func ExampleReinit() {
+	defer zaploggerfilter.Reset()
+	initExample(stdoutConsole("app"))
+
+	err := zaploggerfilter.Reinit([]zaploggerfilter.Config{{Type: "unknown", Name: "app", Level: "info"}})
+	fmt.Println(err != nil)
+	zaploggerfilter.InfoTo("app", "原有日志记录器保持不变")
+	// Output:
+	// true
+	// info 原有日志记录器保持不变
+}
+
+func ExampleReset() {
+	initExample(stdoutConsole("app"))
+	zaploggerfilter.InfoTo("app", "第一次初始化")
+
+	if err := zaploggerfilter.Reset(); err != nil {
+		fmt.Println(err)
+	}
+	fmt.Println(zaploggerfilter.InfoTo("app", "已被移除"))
+
+	initExample(stdoutConsole("app", "password"))
+	defer zaploggerfilter.Reset()
+	zaploggerfilter.InfoTo("app", "第二次初始化", zap.String("password", "secret"))
+	// Output:
+	// info 第一次初始化
+	// false
+	// info 第二次初始化 {"password": "***"}
+}
+
+func ExampleAddTargetLogger() {
+	defer zaploggerfilter.Reset()
+	initExample()
+
+	zaploggerfilter.AddTargetLogger(stdoutConsole("audit", "card"))
+	zaploggerfilter.InfoTo("audit", "支付", zap.String("card", "4111111111111111"))
+	// Output:
+	// info 支付 {"card": "***"}
+}
+
+func ExampleGetTargetLogger() {
+	defer zaploggerfilter.Reset()
+	initExample(stdoutConsole("app", "token"))
+
+	lg, ok := zaploggerfilter.GetTargetLogger("app")
+	fmt.Println(ok)
+	lg.Warn("令牌即将过期", zap.String("token", "abc"))
+
+	_, ok = zaploggerfilter.GetTargetLogger("missing")
+	fmt.Println(ok)
+	// Output:
+	// true
+	// warn 令牌即将过期 {"token": "***"}
+	// false
+}
+
+func ExampleLogTo() {
+	defer zaploggerfilter.Reset()
+	initExample(stdoutConsole("app"))
+
+	fmt.Println(zaploggerfilter.LogTo("app", zapcore.ErrorLevel, "写入失败", zap.Int("attempt", 3)))
+	fmt.Println(zaploggerfilter.LogTo("missing", zapcore.ErrorLevel, "写入失败"))
+	// Output:
+	// error 写入失败 {"attempt": 3}
+	// true
+	// false
+}
+
+func ExampleWithFields() {
+	defer zaploggerfilter.Reset()
+	initExample(stdoutConsole("app", "password"))
+
+	if err := zaploggerfilter.WithFields("app", "app.payment", zap.String("service", "payment")); err != nil {
+		fmt.Println(err)
+	}
+	zaploggerfilter.InfoTo("app.payment", "扣款", zap.String("password", "secret"))
+	// Output:
+	// info 扣款 {"service": "payment", "password": "***"}
+}
+
+func ExampleWithFieldsFilter() {
+	defer zaploggerfilter.Reset()
+	initExample(stdoutConsole("app", "password"))
+
+	paymentFilter := zaploggerfilter.NewSensitiveDataFilter([]string{"card"})
+	if err := zaploggerfilter.WithFieldsFilter("app", "app.payment", paymentFilter); err != nil {
+		fmt.Println(err)
+	}
+	zaploggerfilter.InfoTo("app.payment", "扣款", zap.String("card", "4111111111111111"), zap.String("password", "secret"))
+	// Output:
+	// info 扣款 {"card": "***", "password": "***"}
+}
+
+func ExampleWith() {
+	defer zaploggerfilter.Reset()
+	initExample(stdoutConsole("app", "password"))
+
+	zaploggerfilter.With(zap.String("service", "api"), zap.String("password", "secret"))
+	zaploggerfilter.GetGlobalLogger().Info("启动")
+	// Output:
+	// info 启动 {"service": "api", "password": "***"}
+}
+
+func ExampleSetLevel() {
+	defer zaploggerfilter.Reset()
+	initExample(stdoutConsole("app"))
+
+	if err := zaploggerfilter.SetLevel("app", "warn"); err != nil {
+		fmt.Println(err)
+	}
+	level, _ := zaploggerfilter.GetLevel("app")
+	fmt.Println(level)
+	zaploggerfilter.InfoTo("app", "不会输出")
+	zaploggerfilter.WarnTo("app", "会输出")
+	// Output:
+	// warn
+	// warn 会输出
+}
+
+func ExampleSetMissingLoggerPolicy() {
+	defer zaploggerfilter.Reset()
+	defer zaploggerfilter.SetMissingLoggerPolicy(zaploggerfilter.DropEntry)
+	initExample(stdoutConsole("app"))
+
+	zaploggerfilter.SetMissingLoggerPolicy(zaploggerfilter.UseGlobalLogger)
+	fmt.Println(zaploggerfilter.InfoTo("missing", "改为写入全局日志记录器"))
+	// Output:
+	// info 改为写入全局日志记录器
+	// false
+}
+
+func ExampleEmitEntry() {
+	defer zaploggerfilter.Reset()
+	initExample(stdoutConsole("app", "token"))
+
+	entries := make(chan zaploggerfilter.LogEntry, 1)
+	entries <- zaploggerfilter.LogEntry{
+		Target: "app",
+		Level:  zapcore.InfoLevel,
+		Msg:    "稍后写入",
+		Fields: []zapcore.Field{zap.String("token", "abc")},
+	}
+	close(entries)
+
+	for entry := range entries {
+		zaploggerfilter.EmitEntry(entry)
+	}
+	// Output:
+	// info 稍后写入 {"token": "***"}
+}
+
+func ExampleLogToCtx() {
+	defer zaploggerfilter.Reset()
+	initExample(stdoutConsole("app"))
+
+	ctx := zaploggerfilter.BeginRequest(context.Background(), "req-123")
+	zaploggerfilter.LogToCtx(ctx, "app", zapcore.InfoLevel, "查询完成")
+	// Output:
+	// info 查询完成 {"request_id": "req-123"}
+}
+
+type tenantKey struct{}
+
+func ExampleInfoCtx() {
+	defer zaploggerfilter.Reset()
+	defer zaploggerfilter.SetContextKeys(nil)
+	initExample(stdoutConsole("app"))
+
+	zaploggerfilter.SetContextKeys(map[string]interface{}{"tenant": tenantKey{}})
+	ctx := context.WithValue(context.Background(), tenantKey{}, "acme")
+	zaploggerfilter.InfoCtx(ctx, "处理请求", zap.String("path", "/orders"))
+	zaploggerfilter.WithContext(ctx).Warn("处理较慢")
+	// Output:
+	// info 处理请求 {"tenant": "acme", "path": "/orders"}
+	// warn 处理较慢 {"tenant": "acme"}
+}
+
+func ExampleRegisterNamespace() {
+	defer zaploggerfilter.Reset()
+	initExample(stdoutConsole("db"))
+
+	if err := zaploggerfilter.RegisterNamespace("db", "queries"); err != nil {
+		fmt.Println(err)
+	}
+	zaploggerfilter.InfoTo("db.queries", "慢查询", zap.Int("ms", 120))
+	fmt.Println(zaploggerfilter.ListNamespaceChildren("db"))
+	// Output:
+	// info 慢查询 {"queries": {"ms": 120}}
+	// [queries]
+}
+
+func ExampleLogBatch() {
+	defer zaploggerfilter.Reset()
+	initExample(stdoutConsole("app", "password"))
+
+	err := zaploggerfilter.LogBatch("app", []zaploggerfilter.BatchEntry{
+		{Level: zapcore.InfoLevel, Msg: "第一条"},
+		{Level: zapcore.WarnLevel, Msg: "第二条", Fields: []zapcore.Field{zap.String("password", "secret")}},
+	})
+	fmt.Println(err)
+	// Output:
+	// info 第一条
+	// warn 第二条 {"password": "***"}
+	// <nil>
+}
+
+func ExampleBeginLogTransaction() {
+	defer zaploggerfilter.Reset()
+	initExample(stdoutConsole("app"))
+
+	tx, err := zaploggerfilter.BeginLogTransaction("app")
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+	tx.Info("步骤一")
+	tx.Info("步骤二")
+	fmt.Println("提交前")
+	fmt.Println(zaploggerfilter.CommitLogTransaction(tx))
+	// Output:
+	// 提交前
+	// info 步骤一
+	// info 步骤二
+	// <nil>
+}
+
+func ExampleValidate() {
+	errs := zaploggerfilter.Validate([]zaploggerfilter.Config{
+		{Type: zaploggerfilter.Console, Name: "app", Level: "verbose"},
+	})
+	fmt.Println(len(errs) > 0)
+	// Output:
+	// true
+}
+
+func ExampleNewSensitiveDataFilter() {
+	filter := zaploggerfilter.NewSensitiveDataFilter([]string{"password", "api_key"})
+
+	fmt.Println(filter.IsSensitiveField("Password"))
+	fmt.Println(filter.IsSensitiveField(" API_KEY "))
+	fmt.Println(filter.IsSensitiveField("user"))
+	// Output:
+	// true
+	// true
+	// false
+}
+
+func ExampleNewSensitiveDataFilterWithPatterns() {
+	filter, err := zaploggerfilter.NewSensitiveDataFilterWithPatterns([]string{"password"}, []string{`.*_secret$`})
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+	fmt.Println(filter.IsSensitiveField("client_secret"))
+	fmt.Println(filter.IsSensitiveField("secretary"))
+	// Output:
+	// true
+	// false
+}
+
+func ExampleNewEnvironmentAwareFilter() {
+	lg := newExampleLogger(zaploggerfilter.NewEnvironmentAwareFilter([]string{"password"}, false))
+	lg.Info("开发环境", zap.String("password", "secret"))
+	// Output:
+	// {"level":"info","msg":"开发环境","password":"secret"}
+}
+
+func ExampleSensitiveDataFilter_MaskSensitiveData() {
+	filter := zaploggerfilter.NewSensitiveDataFilter([]string{"password"})
+
+	masked := filter.MaskSensitiveData(map[string]interface{}{
+		"user":     "alice",
+		"password": "secret",
+		"profile":  map[string]interface{}{"password": "nested"},
+	})
+	fmt.Println(masked)
+	// Output:
+	// map[password:*** profile:map[password:***] user:alice]
+}
+
+func ExampleSensitiveDataFilter_Clone() {
+	base := zaploggerfilter.NewSensitiveDataFilter([]string{"password"})
+	payments := base.Clone()
+	payments.AddField("card_number")
+
+	fmt.Println(base.IsSensitiveField("card_number"))
+	fmt.Println(payments.IsSensitiveField("card_number"))
+	fmt.Println(payments.IsSensitiveField("password"))
+	// Output:
+	// false
+	// true
+	// true
+}
+
+func ExampleSensitiveDataFilter_AddAlias() {
+	filter := zaploggerfilter.NewSensitiveDataFilter([]string{"password"})
+	filter.AddAlias("pwd", "password")
+
+	fmt.Println(filter.MaskSensitiveData(map[string]interface{}{"pwd": "secret"}))
+	// Output:
+	// map[pwd:***]
+}
+
+func ExampleSensitiveDataFilter_SetFieldMaskFunc() {
+	filter := zaploggerfilter.NewSensitiveDataFilter(nil)
+	filter.SetFieldMaskFunc("email", func(value interface{}) interface{} {
+		s, _ := value.(string)
+		if i := strings.Index(s, "@"); i >= 0 {
+			return "***" + s[i:]
+		}
+		return "***"
+	})
+	filter.SetFieldMaskFunc("phone", func(value interface{}) interface{} {
+		s := fmt.Sprint(value)
+		if len(s) <= 4 {
+			return "***"
+		}
+		return "***" + s[len(s)-4:]
+	})
+
+	lg := newExampleLogger(filter)
+	lg.Info("注册", zap.String("email", "alice@example.com"), zap.Int64("phone", 13812345678))
+	// Output:
+	// {"level":"info","msg":"注册","email":"***@example.com","phone":"***5678"}
+}
+
+func ExampleSensitiveDataFilter_SetMaskConfig() {
+	filter := zaploggerfilter.NewSensitiveDataFilter([]string{"card"})
+	filter.SetMaskConfig(zaploggerfilter.MaskConfig{Prefix: 4, Suffix: 4, MaskChar: '*'})
+
+	fmt.Println(filter.MaskSensitiveData(map[string]interface{}{"card": "4111111111111111"}))
+	// Output:
+	// map[card:4111********1111]
+}
+
+func ExampleMaskConfig_Mask() {
+	mc := zaploggerfilter.MaskConfig{Prefix: 2, Suffix: 2, MaskChar: '#'}
+
+	fmt.Println(mc.Mask("secret-token"))
+	fmt.Println(mc.Mask("abc"))
+	// Output:
+	// se########en
+	// ###
+}
+
+func ExampleSensitiveDataFilter_AddBuiltinPattern() {
+	filter := zaploggerfilter.NewSensitiveDataFilter(nil)
+	if err := filter.AddBuiltinPattern(zaploggerfilter.BuiltinIPv4); err != nil {
+		fmt.Println(err)
+		return
+	}
+
+	lg := newExampleLogger(filter)
+	lg.Info("连接", zap.String("remote", "client 192.168.1.20 connected"))
+	// Output:
+	// {"level":"info","msg":"连接","remote":"client 192.168.1.*** connected"}
+}
+
+func ExampleSensitiveDataFilter_Merge() {
+	auth := zaploggerfilter.NewSensitiveDataFilter([]string{"password"})
+	payment := zaploggerfilter.NewSensitiveDataFilter([]string{"card"})
+
+	merged := auth.Merge(payment)
+	fmt.Println(merged.IsSensitiveField("password"), merged.IsSensitiveField("card"))
+	// Output:
+	// true true
+}
+
+func ExampleSensitiveDataFilter_Intersection() {
+	a := zaploggerfilter.NewSensitiveDataFilter([]string{"password", "card"})
+	b := zaploggerfilter.NewSensitiveDataFilter([]string{"card", "ssn"})
+
+	common := a.Intersection(b)
+	fmt.Println(common.IsSensitiveField("card"), common.IsSensitiveField("password"), common.IsSensitiveField("ssn"))
+	// Output:
+	// true false false
+}
+
+func ExampleSensitiveDataFilter_AddFieldGroup() {
+	filter := zaploggerfilter.NewSensitiveDataFilter(nil)
+	filter.AddFieldGroup("pii", []string{"email", "phone"})
+	fmt.Println(filter.IsSensitiveField("email"))
+
+	if err := filter.DisableGroup("pii"); err != nil {
+		fmt.Println(err)
+	}
+	fmt.Println(filter.IsSensitiveField("email"))
+	// Output:
+	// true
+	// false
+}
+
+func ExampleSensitiveDataFilter_FilterFields() {
+	filter := zaploggerfilter.NewSensitiveDataFilter([]string{"token"})
+
+	fields := filter.FilterFields([]zapcore.Field{zap.String("token", "abc"), zap.String("user", "alice")})
+	for _, field := range fields {
+		fmt.Println(field.Key, field.String)
+	}
+	// Output:
+	// token ***
+	// user alice
+}
+
+func ExampleSensitiveDataEncoder() {
+	encoder := &zaploggerfilter.SensitiveDataEncoder{
+		Encoder: zapcore.NewJSONEncoder(exampleEncoderConfig),
+		Filter:  zaploggerfilter.NewSensitiveDataFilter([]string{"password"}),
+	}
+	core := zapcore.NewCore(encoder, zapcore.AddSync(os.Stdout), zapcore.InfoLevel)
+	lg := zap.New(core)
+
+	lg.Debug("低于核心级别，不会输出", zap.String("password", "secret"))
+	if ce := lg.Check(zapcore.InfoLevel, "登录"); ce != nil {
+		ce.Write(zap.String("password", "secret"))
+	}
+	// Output:
+	// {"level":"info","msg":"登录","password":"***"}
+}
+
+func ExampleSensitiveDataEncoder_with() {
+	encoder := &zaploggerfilter.SensitiveDataEncoder{
+		Encoder: zapcore.NewJSONEncoder(exampleEncoderConfig),
+		Filter:  zaploggerfilter.NewSensitiveDataFilter([]string{"password"}),
+	}
+	lg := zap.New(zapcore.NewCore(encoder, zapcore.AddSync(os.Stdout), zapcore.InfoLevel))
+
+	// With添加的字段直接写入编码器，同样会被过滤
+	lg.With(zap.String("password", "secret"), zap.String("user", "alice")).Info("登录")
+	// Output:
+	// {"level":"info","msg":"登录","password":"***","user":"alice"}
+}
+
+func ExampleNewJSONEncoderWithFilter() {
+	filter := zaploggerfilter.NewSensitiveDataFilter([]string{"password"})
+	encoder := zaploggerfilter.NewJSONEncoderWithFilter(exampleEncoderConfig, filter)
+	lg := zap.New(zapcore.NewCore(encoder, zapcore.AddSync(os.Stdout), zapcore.DebugLevel))
+
+	lg.Info("登录", zap.Any("request", map[string]interface{}{"user": "alice", "password": "secret"}))
+	// Output:
+	// {"level":"info","msg":"登录","request":{"password":"***","user":"alice"}}
+}
+
+func ExampleNewConsoleEncoderWithFilter() {
+	filter := zaploggerfilter.NewSensitiveDataFilter([]string{"password"})
+	encoder := zaploggerfilter.NewConsoleEncoderWithFilter(exampleEncoderConfig, filter)
+	lg := zap.New(zapcore.NewCore(encoder, zapcore.AddSync(os.Stdout), zapcore.DebugLevel))
+
+	lg.Info("登录", zap.String("password", "secret"))
+	// Output:
+	// info 登录 {"password": "***"}
+}
+
+type exampleUser struct {
+	Name     string `json:"name"`
+	Password string `json:"password"`
+}
+
+func ExampleSensitiveDataMarshaler() {
+	m := &zaploggerfilter.SensitiveDataMarshaler{
+		Data:   exampleUser{Name: "alice", Password: "secret"},
+		Filter: zaploggerfilter.NewSensitiveDataFilter([]string{"password"}),
+	}
+	data, err := m.MarshalJSON()
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+	fmt.Println(string(data))
+	// Output:
+	// {"name":"alice","password":"***"}
+}
+
+func ExampleNewLogfmtEncoder() {
+	encoder := zaploggerfilter.NewLogfmtEncoder(exampleEncoderConfig)
+	lg := zap.New(zapcore.NewCore(encoder, zapcore.AddSync(os.Stdout), zapcore.DebugLevel))
+
+	lg.Info("请求完成", zap.Int("status", 200), zap.String("path", "/a b"))
+	// Output:
+	// level=info msg=请求完成 status=200 path="/a b"
+}
+
+func ExampleNewLevelBoundedCore() {
+	inner := zapcore.NewCore(zapcore.NewJSONEncoder(exampleEncoderConfig), zapcore.AddSync(os.Stdout), zapcore.DebugLevel)
+	lg := zap.New(zaploggerfilter.NewLevelBoundedCore(inner, zapcore.WarnLevel, zapcore.ErrorLevel))
+
+	lg.Info("不在范围内")
+	lg.Error("在范围内")
+	// Output:
+	// {"level":"error","msg":"在范围内"}
+}
+
+func ExampleNewTracedError() {
+	err := zaploggerfilter.NewTracedError(errors.New("connection refused"), zap.String("host", "db"))
+
+	fmt.Println(err)
+	fmt.Println(len(err.(*zaploggerfilter.TracedError).Fields()))
+	// Output:
+	// connection refused
+	// 1
+}
@@ -0,0 +1,31 @@
+package zaploggerfilter
+
+import (
+	"go.uber.org/zap"
+)
+
+// LoggerFactory 日志记录器工厂，使调用方代码不直接依赖包级别的全局状态，便于在测试中注入日志记录器
+// 测试使用的实现见zaploggerfiltertest.NewLoggerFactory
+type LoggerFactory interface {
+	// GetLogger 获取指定名称的日志记录器，返回值不为nil
+	GetLogger(name string) *zap.Logger
+	// SetGlobal 替换全局日志记录器
+	SetGlobal(lg *zap.Logger)
+}
+
+// DefaultLoggerFactory 使用包级别全局状态的日志记录器工厂
+type DefaultLoggerFactory struct{}
+
+// GetLogger 实现LoggerFactory接口，目标不存在时返回不输出任何内容的日志记录器
+func (DefaultLoggerFactory) GetLogger(name string) *zap.Logger {
+	lg, _ := GetTargetLogger(name)
+	return lg
+}
+
+// SetGlobal 实现LoggerFactory接口，替换L
+func (DefaultLoggerFactory) SetGlobal(lg *zap.Logger) {
+	initMu.Lock()
+	defer initMu.Unlock()
+
+	setGlobalLogger(lg, []syncer{lg})
+}
@@ -0,0 +1,52 @@
+package zaploggerfilter
+
+import (
+	"fmt"
+	"os"
+	"sync"
+	"time"
+)
+
+// StartAutoFlush 启动后台goroutine，每隔interval同步一次所有日志记录器
+// 同步错误输出到标准错误（不会写入任何日志记录器，避免递归）
+// interval不大于0时不会启动
+// 返回: 停止函数，停止后台goroutine并执行最后一次同步
+func StartAutoFlush(interval time.Duration) (stop func()) {
+	if interval <= 0 {
+		return func() {}
+	}
+
+	done := make(chan struct{})
+	finished := make(chan struct{})
+	go func() {
+		defer close(finished)
+
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-done:
+				return
+			case <-ticker.C:
+				reportSyncError(syncAll())
+			}
+		}
+	}()
+
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			close(done)
+			<-finished
+			reportSyncError(syncAll())
+		})
+	}
+}
+
+// reportSyncError 将同步错误输出到标准错误
+func reportSyncError(err error) {
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "zaploggerfilter: %v\n", err)
+	}
+}
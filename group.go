@@ -0,0 +1,106 @@
+package zaploggerfilter
+
+import (
+	"fmt"
+	"sort"
+)
+
+// fieldGroup 敏感字段分组
+type fieldGroup struct {
+	// fields 添加时的字段名
+	fields []string
+	// normalized 规范化后的字段名集合
+	normalized map[string]bool
+	enabled    bool
+}
+
+// newFieldGroup 创建启用状态的敏感字段分组
+func newFieldGroup(fields []string) *fieldGroup {
+	g := &fieldGroup{
+		fields:     append([]string(nil), fields...),
+		normalized: make(map[string]bool, len(fields)),
+		enabled:    true,
+	}
+	for _, field := range fields {
+		g.normalized[normalizeFieldName(field)] = true
+	}
+	return g
+}
+
+// clone 复制敏感字段分组
+func (g *fieldGroup) clone() *fieldGroup {
+	c := newFieldGroup(g.fields)
+	c.enabled = g.enabled
+	return c
+}
+
+// AddFieldGroup 添加敏感字段分组，分组内的字段在分组启用时视为敏感字段
+// 新添加的分组默认启用；已存在的同名分组会被替换
+func (f *SensitiveDataFilter) AddFieldGroup(groupName string, fields []string) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if f.groups == nil {
+		f.groups = make(map[string]*fieldGroup)
+	}
+	f.groups[groupName] = newFieldGroup(fields)
+}
+
+// EnableGroup 启用敏感字段分组
+func (f *SensitiveDataFilter) EnableGroup(groupName string) error {
+	return f.setGroupEnabled(groupName, true)
+}
+
+// DisableGroup 停用敏感字段分组，分组内的字段不再视为敏感字段（敏感字段列表中的字段除外），
+// 分组本身仍会保留
+func (f *SensitiveDataFilter) DisableGroup(groupName string) error {
+	return f.setGroupEnabled(groupName, false)
+}
+
+// setGroupEnabled 设置敏感字段分组是否启用
+func (f *SensitiveDataFilter) setGroupEnabled(groupName string, enabled bool) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	g, ok := f.groups[groupName]
+	if !ok {
+		return fmt.Errorf("unknown field group: %s", groupName)
+	}
+	g.enabled = enabled
+	return nil
+}
+
+// ListGroups 列出所有敏感字段分组的名称，按名称排序
+func (f *SensitiveDataFilter) ListGroups() []string {
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+
+	names := make([]string, 0, len(f.groups))
+	for name := range f.groups {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// GroupFields 获取敏感字段分组中的字段，分组不存在时返回false
+func (f *SensitiveDataFilter) GroupFields(groupName string) ([]string, bool) {
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+
+	g, ok := f.groups[groupName]
+	if !ok {
+		return nil, false
+	}
+	return append([]string(nil), g.fields...), true
+}
+
+// inEnabledGroup 判断规范化后的字段名是否属于已启用的分组，调用方需持有锁
+func (f *SensitiveDataFilter) inEnabledGroup(lowerField string) bool {
+	for _, g := range f.groups {
+		if g.enabled && g.normalized[lowerField] {
+			return true
+		}
+	}
+	return false
+}
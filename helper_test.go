@@ -0,0 +1,60 @@
+package zaploggerfilter
+
+import (
+	"bytes"
+	"sync"
+	"testing"
+)
+
+// syncBuffer 并发安全的日志输出缓冲区
+type syncBuffer struct {
+	mu  sync.Mutex
+	buf bytes.Buffer
+}
+
+// Write 实现io.Writer接口
+func (b *syncBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	return b.buf.Write(p)
+}
+
+// Sync 实现zapcore.WriteSyncer接口
+func (b *syncBuffer) Sync() error {
+	return nil
+}
+
+// String 获取已写入的内容
+func (b *syncBuffer) String() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	return b.buf.String()
+}
+
+// bufferConfig 写入buf的控制台日志记录器配置，指定敏感字段时开启敏感数据过滤
+func bufferConfig(name string, buf *syncBuffer, sensitiveFields ...string) Config {
+	return Config{
+		Type:            Console,
+		Name:            name,
+		Level:           "debug",
+		SensitiveFilter: len(sensitiveFields) > 0,
+		SensitiveFields: sensitiveFields,
+		WriteSyncer:     buf,
+	}
+}
+
+// initTestLoggers 使用配置初始化日志记录器，测试结束时恢复初始化之前的状态
+func initTestLoggers(t testing.TB, cfg ...Config) {
+	t.Helper()
+
+	if err := Reinit(cfg); err != nil {
+		t.Fatalf("Reinit() error = %v", err)
+	}
+	t.Cleanup(func() {
+		if err := Reset(); err != nil {
+			t.Errorf("Reset() error = %v", err)
+		}
+	})
+}
@@ -0,0 +1,100 @@
+package zaploggerfilter
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+var (
+	// idleCheckInterval 检查空闲日志记录器的间隔
+	idleCheckInterval = time.Second
+	// idleReaperOnce 保证只启动一个检查空闲日志记录器的后台goroutine
+	idleReaperOnce sync.Once
+)
+
+// startIdleReaper 启动检查空闲日志记录器的后台goroutine，重复调用不会重复启动
+func startIdleReaper() {
+	idleReaperOnce.Do(func() {
+		go func() {
+			ticker := time.NewTicker(idleCheckInterval)
+			defer ticker.Stop()
+
+			for now := range ticker.C {
+				reapIdleLoggers(now)
+			}
+		}()
+	})
+}
+
+// reapIdleLoggers 同步并移除超过IdleTimeout没有写入的日志记录器，并关闭其日志文件和远程连接等输出
+// 共享统计计数器的派生日志记录器一并移除，全局日志记录器仍在使用其输出时不会过期
+// 同步或关闭错误输出到标准错误
+func reapIdleLoggers(now time.Time) {
+	global := make(map[io.Closer]bool)
+	if syncers := globalSyncers.Load(); syncers != nil {
+		for _, c := range closableOutputs(*syncers) {
+			global[c] = true
+		}
+	}
+
+	l.Range(func(k, v interface{}) bool {
+		nl := v.(*namedLogger)
+		if nl.idleTimeout <= 0 || now.UnixNano()-nl.counters.lastActive() < int64(nl.idleTimeout) {
+			return true
+		}
+		if usesOutputs(nl.syncers, global) {
+			return true
+		}
+		// 期间被替换的日志记录器不会被移除
+		if !l.CompareAndDelete(k, nl) {
+			return true
+		}
+		l.Range(func(dk, dv interface{}) bool {
+			if derived := dv.(*namedLogger); derived.counters == nl.counters {
+				l.CompareAndDelete(dk, derived)
+			}
+			return true
+		})
+		reportSyncError(errors.Join(
+			syncNamedLogger(fmt.Sprintf("logger %q", k), nl),
+			closeOutputs(nl.syncers, make(map[io.Closer]bool)),
+		))
+		return true
+	})
+}
+
+// syncNamedLogger 同步命名日志记录器的底层输出
+func syncNamedLogger(name string, nl *namedLogger) error {
+	syncers := nl.syncers
+	if syncers == nil {
+		syncers = []syncer{nl.logger}
+	}
+
+	var errs []error
+	for _, s := range syncers {
+		if err := s.Sync(); err != nil && !isIgnorableSyncError(err) {
+			errs = append(errs, fmt.Errorf("failed to sync %s: %w", name, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// GetOrCreateLogger 获取配置中名称对应的日志记录器，不存在（包括因空闲过期被移除）时按配置创建
+// 配置无效时返回错误
+func GetOrCreateLogger(c Config) (*zap.Logger, error) {
+	if nl, ok := loadLogger(c.Name); ok {
+		return nl.logger, nil
+	}
+
+	nl, err := newNamedLogger(c, activeEncoderConfig())
+	if err != nil {
+		return nil, fmt.Errorf("invalid config %q: %w", c.Name, err)
+	}
+	actual, _ := l.LoadOrStore(c.Name, nl)
+	return actual.(*namedLogger).logger, nil
+}
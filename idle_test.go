@@ -0,0 +1,82 @@
+package zaploggerfilter
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"go.uber.org/zap/zapcore"
+)
+
+// openFile 判断当前进程是否打开了给定路径的文件，不支持/proc时跳过测试
+func openFile(t *testing.T, path string) bool {
+	t.Helper()
+
+	entries, err := os.ReadDir("/proc/self/fd")
+	if err != nil {
+		t.Skipf("/proc/self/fd not available: %v", err)
+	}
+	for _, e := range entries {
+		if target, err := os.Readlink(filepath.Join("/proc/self/fd", e.Name())); err == nil && target == path {
+			return true
+		}
+	}
+	return false
+}
+
+func TestReapIdleLoggersClosesFiles(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "tenant.log")
+	var buf syncBuffer
+	cfg := []Config{
+		bufferConfig("main", &buf),
+		{Type: File, Name: "tenant", Level: "info", Path: path, IdleTimeout: time.Minute},
+	}
+	if err := ReinitWithOptions(cfg, InitOptions{GlobalCores: []string{"main"}}); err != nil {
+		t.Fatalf("ReinitWithOptions() error = %v", err)
+	}
+	t.Cleanup(func() { _ = Reset() })
+
+	if err := WithFields("tenant", "tenant.request"); err != nil {
+		t.Fatalf("WithFields() error = %v", err)
+	}
+	LogTo("tenant", zapcore.InfoLevel, "hello")
+	if !openFile(t, path) {
+		t.Fatalf("log file %s not open after write", path)
+	}
+
+	reapIdleLoggers(time.Now().Add(time.Hour))
+
+	for _, name := range []string{"tenant", "tenant.request"} {
+		if _, ok := loadLogger(name); ok {
+			t.Errorf("logger %q not removed after idle timeout", name)
+		}
+	}
+	if openFile(t, path) {
+		t.Errorf("log file %s still open after idle timeout", path)
+	}
+
+	logger, err := GetOrCreateLogger(cfg[1])
+	if err != nil {
+		t.Fatalf("GetOrCreateLogger() error = %v", err)
+	}
+	logger.Info("again")
+	if _, ok := loadLogger("tenant"); !ok {
+		t.Errorf("logger %q not recreated", "tenant")
+	}
+}
+
+func TestReapIdleLoggersKeepsGlobalOutputs(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "app.log")
+	initTestLoggers(t, Config{Type: File, Name: "app", Level: "info", Path: path, IdleTimeout: time.Minute})
+
+	LogTo("app", zapcore.InfoLevel, "hello")
+	reapIdleLoggers(time.Now().Add(time.Hour))
+
+	if _, ok := loadLogger("app"); !ok {
+		t.Errorf("logger %q used by the global logger removed after idle timeout", "app")
+	}
+	if !openFile(t, path) {
+		t.Errorf("log file %s used by the global logger closed after idle timeout", path)
+	}
+}
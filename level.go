@@ -0,0 +1,94 @@
+package zaploggerfilter
+
+import (
+	"fmt"
+
+	"go.uber.org/zap/zapcore"
+)
+
+// SetLevel 在运行时修改目标日志记录器的日志级别，对并发的日志调用立即生效，不需要重新初始化
+// 通过WithFields等方式派生的日志记录器与父日志记录器共享级别，修改其中任意一个会同时影响两者；
+// 自定义类型的日志核心由CoreBuilder按配置的级别创建，只能将级别提高到配置的级别以上
+// 返回: 目标不存在时返回ErrLoggerNotFound，级别无效时返回错误
+func SetLevel(name string, level string) error {
+	lvl, err := parseLoggerLevel(level)
+	if err != nil {
+		return err
+	}
+	nl, ok := loadLogger(name)
+	if !ok {
+		return fmt.Errorf("%w: %s", ErrLoggerNotFound, name)
+	}
+	nl.level.SetLevel(lvl)
+	return nil
+}
+
+// GetLevel 获取目标日志记录器当前的日志级别
+// 返回: 级别名称（如 "info"），以及目标日志记录器是否存在
+func GetLevel(name string) (string, bool) {
+	nl, ok := loadLogger(name)
+	if !ok {
+		return "", false
+	}
+	return nl.level.Level().String(), true
+}
+
+// levelGatedCore 按动态日志级别过滤日志条目的日志核心，用于无法直接使用zap.AtomicLevel的自定义日志核心
+type levelGatedCore struct {
+	zapcore.Core
+	level zapcore.LevelEnabler
+}
+
+// Enabled 实现zapcore.LevelEnabler接口
+func (c *levelGatedCore) Enabled(lvl zapcore.Level) bool {
+	return c.level.Enabled(lvl) && c.Core.Enabled(lvl)
+}
+
+// With 实现zapcore.Core接口
+func (c *levelGatedCore) With(fields []zapcore.Field) zapcore.Core {
+	return &levelGatedCore{Core: c.Core.With(fields), level: c.level}
+}
+
+// Check 实现zapcore.Core接口
+func (c *levelGatedCore) Check(ent zapcore.Entry, ce *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	if !c.level.Enabled(ent.Level) {
+		return ce
+	}
+	return c.Core.Check(ent, ce)
+}
+
+// levelBoundedCore 只输出指定级别范围内日志条目的日志核心
+type levelBoundedCore struct {
+	zapcore.Core
+	min zapcore.Level
+	max zapcore.Level
+}
+
+// NewLevelBoundedCore 包装日志核心，只输出级别满足 min <= level <= max 的日志条目
+// 例如只向告警渠道发送panic和fatal级别的日志：NewLevelBoundedCore(core, zapcore.PanicLevel, zapcore.FatalLevel)
+func NewLevelBoundedCore(inner zapcore.Core, min, max zapcore.Level) zapcore.Core {
+	return &levelBoundedCore{Core: inner, min: min, max: max}
+}
+
+// inRange 判断日志级别是否在范围内
+func (c *levelBoundedCore) inRange(lvl zapcore.Level) bool {
+	return lvl >= c.min && lvl <= c.max
+}
+
+// Enabled 实现zapcore.LevelEnabler接口
+func (c *levelBoundedCore) Enabled(lvl zapcore.Level) bool {
+	return c.inRange(lvl) && c.Core.Enabled(lvl)
+}
+
+// With 实现zapcore.Core接口
+func (c *levelBoundedCore) With(fields []zapcore.Field) zapcore.Core {
+	return &levelBoundedCore{Core: c.Core.With(fields), min: c.min, max: c.max}
+}
+
+// Check 实现zapcore.Core接口，范围外的日志条目不会交给内部日志核心
+func (c *levelBoundedCore) Check(ent zapcore.Entry, ce *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	if !c.inRange(ent.Level) {
+		return ce
+	}
+	return c.Core.Check(ent, ce)
+}
@@ -0,0 +1,326 @@
+package zaploggerfilter
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"go.uber.org/zap/buffer"
+	"go.uber.org/zap/zapcore"
+)
+
+// logfmtPool logfmt编码器使用的缓冲池
+var logfmtPool = buffer.NewPool()
+
+// NewLogfmtEncoder 创建以logfmt格式（key=value key2=value2）输出日志的编码器，用于Grafana Agent、Promtail等日志采集工具
+// 包含空格、等号、双引号或控制字符的值会被加上双引号并转义；嵌套对象展开为 "parent.child=value" 形式的键，
+// 数组和通过反射序列化的值输出为JSON字符串。可以与SensitiveDataEncoder组合使用
+func NewLogfmtEncoder(cfg zapcore.EncoderConfig) zapcore.Encoder {
+	return &logfmtEncoder{cfg: cfg, buf: logfmtPool.Get()}
+}
+
+// logfmtEncoder logfmt格式的编码器
+type logfmtEncoder struct {
+	cfg zapcore.EncoderConfig
+	// buf 已编码的字段，通过With添加的字段保存在这里
+	buf *buffer.Buffer
+	// prefix 当前的键名前缀，由打开的命名空间和正在编码的嵌套对象组成
+	prefix []string
+}
+
+// Clone 实现zapcore.Encoder接口
+func (e *logfmtEncoder) Clone() zapcore.Encoder {
+	clone := &logfmtEncoder{
+		cfg:    e.cfg,
+		buf:    logfmtPool.Get(),
+		prefix: append([]string(nil), e.prefix...),
+	}
+	_, _ = clone.buf.Write(e.buf.Bytes())
+	return clone
+}
+
+// EncodeEntry 实现zapcore.Encoder接口
+// 依次输出时间、级别、日志记录器名称、调用位置、函数名、消息、通过With添加的字段、日志字段和堆栈
+func (e *logfmtEncoder) EncodeEntry(ent zapcore.Entry, fields []zapcore.Field) (*buffer.Buffer, error) {
+	final := &logfmtEncoder{cfg: e.cfg, buf: logfmtPool.Get()}
+
+	if e.cfg.TimeKey != "" && !ent.Time.IsZero() {
+		final.AddTime(e.cfg.TimeKey, ent.Time)
+	}
+	if e.cfg.LevelKey != "" && e.cfg.EncodeLevel != nil {
+		final.addKey(e.cfg.LevelKey)
+		final.appendEncoded(func(enc zapcore.PrimitiveArrayEncoder) { e.cfg.EncodeLevel(ent.Level, enc) })
+	}
+	if ent.LoggerName != "" && e.cfg.NameKey != "" {
+		encodeName := e.cfg.EncodeName
+		if encodeName == nil {
+			encodeName = zapcore.FullNameEncoder
+		}
+		final.addKey(e.cfg.NameKey)
+		final.appendEncoded(func(enc zapcore.PrimitiveArrayEncoder) { encodeName(ent.LoggerName, enc) })
+	}
+	if ent.Caller.Defined {
+		if e.cfg.CallerKey != "" && e.cfg.EncodeCaller != nil {
+			final.addKey(e.cfg.CallerKey)
+			final.appendEncoded(func(enc zapcore.PrimitiveArrayEncoder) { e.cfg.EncodeCaller(ent.Caller, enc) })
+		}
+		if e.cfg.FunctionKey != "" {
+			final.AddString(e.cfg.FunctionKey, ent.Caller.Function)
+		}
+	}
+	if e.cfg.MessageKey != "" {
+		final.AddString(e.cfg.MessageKey, ent.Message)
+	}
+
+	if e.buf.Len() > 0 {
+		if final.buf.Len() > 0 {
+			final.buf.AppendByte(' ')
+		}
+		_, _ = final.buf.Write(e.buf.Bytes())
+	}
+	final.prefix = append(final.prefix, e.prefix...)
+	for _, field := range fields {
+		field.AddTo(final)
+	}
+	final.prefix = nil
+
+	if ent.Stack != "" && e.cfg.StacktraceKey != "" {
+		final.AddString(e.cfg.StacktraceKey, ent.Stack)
+	}
+	if e.cfg.SkipLineEnding {
+		return final.buf, nil
+	}
+	if e.cfg.LineEnding != "" {
+		final.buf.AppendString(e.cfg.LineEnding)
+	} else {
+		final.buf.AppendString(zapcore.DefaultLineEnding)
+	}
+	return final.buf, nil
+}
+
+// addKey 写入键名和等号，键名带有当前前缀，其中的空格、等号、双引号和控制字符替换为下划线
+func (e *logfmtEncoder) addKey(key string) {
+	if e.buf.Len() > 0 {
+		e.buf.AppendByte(' ')
+	}
+	for _, p := range e.prefix {
+		appendLogfmtKey(e.buf, p)
+		e.buf.AppendByte('.')
+	}
+	appendLogfmtKey(e.buf, key)
+	e.buf.AppendByte('=')
+}
+
+// appendLogfmtKey 写入键名，替换logfmt键名中不允许的字符
+func appendLogfmtKey(buf *buffer.Buffer, key string) {
+	if !strings.ContainsFunc(key, invalidLogfmtKeyRune) {
+		buf.AppendString(key)
+		return
+	}
+	for _, r := range key {
+		if invalidLogfmtKeyRune(r) {
+			r = '_'
+		}
+		buf.AppendString(string(r))
+	}
+}
+
+// invalidLogfmtKeyRune 判断字符是否不能出现在logfmt键名中
+func invalidLogfmtKeyRune(r rune) bool {
+	return r <= ' ' || r == '=' || r == '"' || r == utf8.RuneError || r == 0x7f
+}
+
+// appendValue 写入值，需要时加上双引号并转义
+func (e *logfmtEncoder) appendValue(value string) {
+	if logfmtNeedsQuote(value) {
+		e.buf.AppendString(strconv.Quote(value))
+		return
+	}
+	e.buf.AppendString(value)
+}
+
+// logfmtNeedsQuote 判断值是否需要加上双引号：空值，或包含空格、等号、双引号、反斜杠、控制字符及无效的UTF-8编码
+func logfmtNeedsQuote(value string) bool {
+	return value == "" || strings.ContainsFunc(value, func(r rune) bool {
+		return r == '\\' || invalidLogfmtKeyRune(r)
+	})
+}
+
+// appendEncoded 写入由zapcore的时间、级别、调用位置等编码函数生成的值
+func (e *logfmtEncoder) appendEncoded(encode func(zapcore.PrimitiveArrayEncoder)) {
+	var values logfmtValues
+	encode(&values)
+	e.appendValue(strings.Join(values, ","))
+}
+
+// AddArray 实现zapcore.ObjectEncoder接口，数组输出为JSON字符串
+func (e *logfmtEncoder) AddArray(key string, arr zapcore.ArrayMarshaler) error {
+	enc := zapcore.NewMapObjectEncoder()
+	if err := enc.AddArray(key, arr); err != nil {
+		return err
+	}
+	return e.AddReflected(key, enc.Fields[key])
+}
+
+// AddObject 实现zapcore.ObjectEncoder接口，对象的字段展开为 "key.field" 形式的键
+// 对象中打开的命名空间在对象结束时关闭
+func (e *logfmtEncoder) AddObject(key string, obj zapcore.ObjectMarshaler) error {
+	n := len(e.prefix)
+	e.prefix = append(e.prefix, key)
+	err := obj.MarshalLogObject(e)
+	e.prefix = e.prefix[:n]
+	return err
+}
+
+// AddBinary 实现zapcore.ObjectEncoder接口，输出为base64字符串
+func (e *logfmtEncoder) AddBinary(key string, value []byte) {
+	e.AddString(key, base64.StdEncoding.EncodeToString(value))
+}
+
+// AddByteString 实现zapcore.ObjectEncoder接口
+func (e *logfmtEncoder) AddByteString(key string, value []byte) {
+	e.AddString(key, string(value))
+}
+
+// AddBool 实现zapcore.ObjectEncoder接口
+func (e *logfmtEncoder) AddBool(key string, value bool) {
+	e.addKey(key)
+	e.buf.AppendBool(value)
+}
+
+// AddComplex128 实现zapcore.ObjectEncoder接口
+func (e *logfmtEncoder) AddComplex128(key string, value complex128) {
+	e.addKey(key)
+	e.buf.AppendString(strconv.FormatComplex(value, 'g', -1, 128))
+}
+
+// AddComplex64 实现zapcore.ObjectEncoder接口
+func (e *logfmtEncoder) AddComplex64(key string, value complex64) {
+	e.addKey(key)
+	e.buf.AppendString(strconv.FormatComplex(complex128(value), 'g', -1, 64))
+}
+
+// AddDuration 实现zapcore.ObjectEncoder接口，使用EncodeDuration编码，未设置时输出纳秒数
+func (e *logfmtEncoder) AddDuration(key string, value time.Duration) {
+	if e.cfg.EncodeDuration == nil {
+		e.AddInt64(key, int64(value))
+		return
+	}
+	e.addKey(key)
+	e.appendEncoded(func(enc zapcore.PrimitiveArrayEncoder) { e.cfg.EncodeDuration(value, enc) })
+}
+
+// AddFloat64 实现zapcore.ObjectEncoder接口
+func (e *logfmtEncoder) AddFloat64(key string, value float64) {
+	e.addKey(key)
+	e.buf.AppendFloat(value, 64)
+}
+
+// AddFloat32 实现zapcore.ObjectEncoder接口
+func (e *logfmtEncoder) AddFloat32(key string, value float32) {
+	e.addKey(key)
+	e.buf.AppendFloat(float64(value), 32)
+}
+
+// AddInt 实现zapcore.ObjectEncoder接口
+func (e *logfmtEncoder) AddInt(key string, value int) { e.AddInt64(key, int64(value)) }
+
+// AddInt32 实现zapcore.ObjectEncoder接口
+func (e *logfmtEncoder) AddInt32(key string, value int32) { e.AddInt64(key, int64(value)) }
+
+// AddInt16 实现zapcore.ObjectEncoder接口
+func (e *logfmtEncoder) AddInt16(key string, value int16) { e.AddInt64(key, int64(value)) }
+
+// AddInt8 实现zapcore.ObjectEncoder接口
+func (e *logfmtEncoder) AddInt8(key string, value int8) { e.AddInt64(key, int64(value)) }
+
+// AddInt64 实现zapcore.ObjectEncoder接口
+func (e *logfmtEncoder) AddInt64(key string, value int64) {
+	e.addKey(key)
+	e.buf.AppendInt(value)
+}
+
+// AddString 实现zapcore.ObjectEncoder接口
+func (e *logfmtEncoder) AddString(key, value string) {
+	e.addKey(key)
+	e.appendValue(value)
+}
+
+// AddTime 实现zapcore.ObjectEncoder接口，使用EncodeTime编码，未设置时输出Unix纳秒时间戳
+func (e *logfmtEncoder) AddTime(key string, value time.Time) {
+	if e.cfg.EncodeTime == nil {
+		e.AddInt64(key, value.UnixNano())
+		return
+	}
+	e.addKey(key)
+	e.appendEncoded(func(enc zapcore.PrimitiveArrayEncoder) { e.cfg.EncodeTime(value, enc) })
+}
+
+// AddUint 实现zapcore.ObjectEncoder接口
+func (e *logfmtEncoder) AddUint(key string, value uint) { e.AddUint64(key, uint64(value)) }
+
+// AddUint32 实现zapcore.ObjectEncoder接口
+func (e *logfmtEncoder) AddUint32(key string, value uint32) { e.AddUint64(key, uint64(value)) }
+
+// AddUint16 实现zapcore.ObjectEncoder接口
+func (e *logfmtEncoder) AddUint16(key string, value uint16) { e.AddUint64(key, uint64(value)) }
+
+// AddUint8 实现zapcore.ObjectEncoder接口
+func (e *logfmtEncoder) AddUint8(key string, value uint8) { e.AddUint64(key, uint64(value)) }
+
+// AddUintptr 实现zapcore.ObjectEncoder接口
+func (e *logfmtEncoder) AddUintptr(key string, value uintptr) { e.AddUint64(key, uint64(value)) }
+
+// AddUint64 实现zapcore.ObjectEncoder接口
+func (e *logfmtEncoder) AddUint64(key string, value uint64) {
+	e.addKey(key)
+	e.buf.AppendUint(value)
+}
+
+// AddReflected 实现zapcore.ObjectEncoder接口，值输出为JSON字符串
+func (e *logfmtEncoder) AddReflected(key string, value interface{}) error {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return err
+	}
+	e.addKey(key)
+	e.appendValue(string(data))
+	return nil
+}
+
+// OpenNamespace 实现zapcore.ObjectEncoder接口，之后的字段键名带有 "key." 前缀
+func (e *logfmtEncoder) OpenNamespace(key string) {
+	e.prefix = append(e.prefix, key)
+}
+
+// logfmtValues 收集zapcore编码函数（如EncodeTime、EncodeLevel）输出的值
+// 以下Append方法实现zapcore.PrimitiveArrayEncoder接口
+type logfmtValues []string
+
+func (v *logfmtValues) AppendBool(b bool)         { *v = append(*v, strconv.FormatBool(b)) }
+func (v *logfmtValues) AppendByteString(b []byte) { *v = append(*v, string(b)) }
+func (v *logfmtValues) AppendComplex128(c complex128) {
+	*v = append(*v, strconv.FormatComplex(c, 'g', -1, 128))
+}
+func (v *logfmtValues) AppendComplex64(c complex64) {
+	*v = append(*v, strconv.FormatComplex(complex128(c), 'g', -1, 64))
+}
+func (v *logfmtValues) AppendFloat64(f float64) { *v = append(*v, strconv.FormatFloat(f, 'f', -1, 64)) }
+func (v *logfmtValues) AppendFloat32(f float32) {
+	*v = append(*v, strconv.FormatFloat(float64(f), 'f', -1, 32))
+}
+func (v *logfmtValues) AppendInt(i int)         { v.AppendInt64(int64(i)) }
+func (v *logfmtValues) AppendInt64(i int64)     { *v = append(*v, strconv.FormatInt(i, 10)) }
+func (v *logfmtValues) AppendInt32(i int32)     { v.AppendInt64(int64(i)) }
+func (v *logfmtValues) AppendInt16(i int16)     { v.AppendInt64(int64(i)) }
+func (v *logfmtValues) AppendInt8(i int8)       { v.AppendInt64(int64(i)) }
+func (v *logfmtValues) AppendString(s string)   { *v = append(*v, s) }
+func (v *logfmtValues) AppendUint(u uint)       { v.AppendUint64(uint64(u)) }
+func (v *logfmtValues) AppendUint64(u uint64)   { *v = append(*v, strconv.FormatUint(u, 10)) }
+func (v *logfmtValues) AppendUint32(u uint32)   { v.AppendUint64(uint64(u)) }
+func (v *logfmtValues) AppendUint16(u uint16)   { v.AppendUint64(uint64(u)) }
+func (v *logfmtValues) AppendUint8(u uint8)     { v.AppendUint64(uint64(u)) }
+func (v *logfmtValues) AppendUintptr(u uintptr) { v.AppendUint64(uint64(u)) }
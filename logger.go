@@ -1,40 +1,86 @@
 package zaploggerfilter
 
 import (
+	"errors"
+	"fmt"
+	"io"
 	"os"
+	"reflect"
+	"slices"
 	"sync"
+	"sync/atomic"
+	"syscall"
+	"time"
 
 	"go.uber.org/zap"
 	"go.uber.org/zap/zapcore"
-	"gopkg.in/natefinch/lumberjack.v2"
 )
 
+// ZapCoreType 日志记录器的输出类型，除内置类型外也可以使用通过RegisterCoreBuilder注册的类型
 type ZapCoreType string
 
 const (
+	// Console 输出到标准输出
 	Console ZapCoreType = "console"
-	File    ZapCoreType = "file"
+	// File 输出到日志文件，按大小轮转
+	File ZapCoreType = "file"
+	// Splunk 输出到Splunk HEC接口，需导入splunksink包
+	Splunk ZapCoreType = "splunk"
+	// NATS 发布到NATS（包括JetStream）的主题，需导入natssink包
+	NATS ZapCoreType = "nats"
+	// LogfmtConsole 以logfmt格式输出到标准输出
+	LogfmtConsole ZapCoreType = "logfmt-console"
+	// LogfmtFile 以logfmt格式输出到日志文件，与File相同按大小轮转
+	LogfmtFile ZapCoreType = "logfmt-file"
 )
 
+// Config 日志记录器配置，各字段的说明见README
 type Config struct {
-	Type            ZapCoreType
-	Name            string
-	Level           string
-	SensitiveFilter bool
-	SensitiveFields []string
-	Path            string
-	MaxSize         int
-	MaxAge          int
-	MaxBackups      int
-	Compress        bool
+	Type             ZapCoreType
+	Name             string
+	Level            string
+	SensitiveFilter  bool
+	SensitiveFields  []string
+	Path             string
+	Paths            []string
+	MaxSize          int
+	MaxSizeBytes     int64
+	MaxAge           int
+	MaxBackups       int
+	Compress         bool
+	LocalTime        bool
+	FilenameTemplate string
+	TimeFormat       string
+	ColorOutput      bool
+	PrettyJSON       bool
+	WriteSyncer      zapcore.WriteSyncer `json:"-" yaml:"-"`
+	IdleTimeout      time.Duration
+	MinIntervalMs    map[string]int
+	// Splunk 类型的配置
+	HECUrl                   string
+	HECToken                 string
+	SplunkIndex              string
+	Source                   string
+	SplunkInsecureSkipVerify bool
+	// NATS 类型的配置
+	Servers              []string
+	Subject              string
+	NATSReconnectBufSize int
 }
 
 var (
-	// L 全局日志记录器
-	L *zap.Logger
-	// l 日志记录器映射
+	// L 全局日志记录器，初始化前为不输出任何内容的日志记录器
+	// 与Reinit并发使用时请通过GetGlobalLogger获取
+	L = nopLogger
+	// nopLogger 初始化前使用的日志记录器
+	nopLogger = zap.NewNop()
+	// globalLogger 全局日志记录器，保证并发读取与替换的安全
+	globalLogger atomic.Pointer[zap.Logger]
+	// globalSyncers 全局日志记录器的底层输出
+	globalSyncers atomic.Pointer[[]syncer]
+	// l 日志记录器映射，值为*namedLogger
 	l sync.Map
-	// encoderConfig 日志编码器配置
+	// encoderConfig 库默认的日志编码器配置，初始化时可通过WithEncoderConfig替换
 	encoderConfig = zapcore.EncoderConfig{
 		TimeKey:        "time",
 		LevelKey:       "level",
@@ -48,158 +94,812 @@ var (
 		EncodeDuration: zapcore.MillisDurationEncoder,
 		EncodeCaller:   zapcore.ShortCallerEncoder,
 	}
+	// DefaultLogLevel 默认日志记录器的日志级别
 	DefaultLogLevel = zapcore.DebugLevel
-	DefaultLogName  = "default"
-	once            sync.Once
+	// DefaultLogName 默认日志记录器的名称
+	DefaultLogName = "default"
+	// initMu 保护初始化过程
+	initMu sync.Mutex
+	// initialized 是否已成功初始化
+	initialized bool
+	// initEncoderConfig 本次初始化通过WithEncoderConfig设置的编码器配置，未设置时为nil
+	initEncoderConfig atomic.Pointer[zapcore.EncoderConfig]
 )
 
-// Init 初始化日志记录器
+// activeEncoderConfig 返回当前初始化使用的编码器配置，未通过WithEncoderConfig设置时为库默认配置
+func activeEncoderConfig() zapcore.EncoderConfig {
+	if ec := initEncoderConfig.Load(); ec != nil {
+		return *ec
+	}
+	return encoderConfig
+}
+
+// ErrLoggerNotFound 目标日志记录器不存在
+var ErrLoggerNotFound = errors.New("target logger not found")
+
+// ErrAlreadyInitialized 日志记录器已经初始化，InitWithOptions不会重复初始化
+var ErrAlreadyInitialized = errors.New("logger already initialized")
+
+// namedLogger 日志记录器映射中存储的命名日志记录器
+//
+// 并发模型：namedLogger存储到映射后不再修改，替换日志记录器时存储新的namedLogger，
+// 已经取得旧值的goroutine可以继续安全地使用旧的日志记录器。
+// 需要在原地修改的状态（如动态日志级别）必须使用原子类型（如zap.AtomicLevel）保存，
+// 以保证修改对并发的读取方可见
+type namedLogger struct {
+	logger *zap.Logger
+	// filter 日志记录器使用的敏感数据过滤器，未开启敏感数据过滤时为nil
+	filter *SensitiveDataFilter
+	// syncers 日志记录器的底层输出，Sync时按实例去重；为nil时直接同步logger
+	syncers []syncer
+	// batch 批量写入使用的输出，派生的日志记录器共享；为nil时LogBatch逐条写入
+	batch *batchWriteSyncer
+	// idleTimeout 空闲过期时间，为0时不会过期
+	idleTimeout time.Duration
+	// counters 日志记录器的统计计数器，派生的日志记录器共享父日志记录器的计数器
+	counters *loggerCounters
+	// level 日志记录器的动态日志级别，派生的日志记录器共享父日志记录器的级别
+	level zap.AtomicLevel
+	// namespace 派生时通过zap.Namespace打开的命名空间路径，用于匹配带命名空间前缀的敏感字段
+	namespace string
+	// derived 是否为通过WithFields等派生的日志记录器
+	derived bool
+}
+
+// syncer 可同步的底层输出，如zapcore.WriteSyncer或无法获取底层输出的日志核心
+type syncer interface {
+	Sync() error
+}
+
+// Init 初始化日志记录器，可以多次调用，每次调用都会与Reinit一样按配置重新初始化
+// 只需初始化一次时使用InitOnce；配置无效时会触发panic，且不会修改已有的日志记录器
+//
+// Deprecated: 使用InitOnce或Reinit，其会返回配置错误
 func Init(cfg []Config) {
-	once.Do(func() {
-		// 创建默认日志记录器核心
-		defaultLogCore := zapcore.NewCore(zapcore.NewConsoleEncoder(encoderConfig), zapcore.AddSync(os.Stdout), DefaultLogLevel)
-		defaultLog := newLogger(defaultLogCore)
-		l.Store(DefaultLogName, defaultLog)
-
-		if len(cfg) > 0 {
-			// 创建日志记录器核心
-			cores := make([]zapcore.Core, 0, len(cfg))
-			for _, c := range cfg {
-				core := newCore(c)
-				cores = append(cores, core)
-				l.Store(c.Name, newLogger(core))
+	if err := reinit(WithConfigs(cfg)); err != nil {
+		panic(err)
+	}
+}
+
+// InitOptions 初始化选项
+type InitOptions struct {
+	// GlobalCores 合并到全局日志记录器L中的日志记录器名称，为空时合并所有配置的日志记录器
+	GlobalCores []string
+}
+
+// InitOnce 初始化日志记录器，仅第一次成功的调用会执行初始化
+// 返回: 本次调用是否执行了初始化；配置无效时返回错误，且不会修改已有的日志记录器
+func InitOnce(cfg []Config) (bool, error) {
+	return InitOnceWithOptions(cfg, InitOptions{})
+}
+
+// InitOnceWithOptions 使用初始化选项初始化日志记录器，行为与InitOnce一致
+func InitOnceWithOptions(cfg []Config, opts InitOptions) (bool, error) {
+	return initOnce(WithConfigs(cfg), opts)
+}
+
+// Reinit 重新初始化日志记录器，替换全局日志记录器以及配置中的同名日志记录器
+// 其他日志记录器（如通过AddTargetLogger添加的）保持不变
+// 配置无效时返回错误，且不会修改已有的日志记录器
+func Reinit(cfg []Config) error {
+	return ReinitWithOptions(cfg, InitOptions{})
+}
+
+// ReinitWithOptions 使用初始化选项重新初始化日志记录器，行为与Reinit一致
+func ReinitWithOptions(cfg []Config, opts InitOptions) error {
+	return reinit(WithConfigs(cfg), opts)
+}
+
+// Reset 同步并移除所有日志记录器，关闭其日志文件和远程连接等输出，恢复到初始化之前的状态，之后可以再次调用InitOnce等初始化函数
+// 全局日志记录器L恢复为不输出任何内容的日志记录器，WithEncoderConfig设置的编码器配置不再生效
+// 仅用于测试或进程内的配置重载，不能与记录日志并发调用
+// 返回: 同步和关闭输出的错误，忽略标准输出等不支持同步的输出产生的错误
+func Reset() error {
+	initMu.Lock()
+	defer initMu.Unlock()
+
+	errs := []error{syncAll()}
+	closed := make(map[io.Closer]bool)
+	if syncers := globalSyncers.Load(); syncers != nil {
+		errs = append(errs, closeOutputs(*syncers, closed))
+	}
+	l.Range(func(k, v interface{}) bool {
+		errs = append(errs, closeOutputs(v.(*namedLogger).syncers, closed))
+		l.Delete(k)
+		return true
+	})
+	globalSyncers.Store(nil)
+	globalLogger.Store(nil)
+	L = nopLogger
+	initEncoderConfig.Store(nil)
+	initialized = false
+	return errors.Join(errs...)
+}
+
+// initLoggers 根据配置创建并存储日志记录器
+// 所有配置都创建成功后才会存储，配置中存在问题的组合会以警告输出到标准错误，调用方需持有initMu
+func initLoggers(s initSettings) error {
+	cfg, opts := s.configs, s.options
+	encCfg := encoderConfig
+	if s.encoderConfig != nil {
+		encCfg = *s.encoderConfig
+	}
+	defaultLogLevel := DefaultLogLevel
+	if s.defaultLevel != nil {
+		defaultLogLevel = *s.defaultLevel
+	}
+
+	// 合法但可能存在问题的配置只输出警告，不影响初始化
+	for _, w := range validateWarnings(cfg) {
+		fmt.Fprintf(os.Stderr, "zaploggerfilter: warning: %v\n", w)
+	}
+	// 多个文件日志记录器使用同一路径时，在创建任何文件之前返回错误
+	conflicts := pathConflicts(cfg)
+	for i, c := range cfg {
+		if problems := conflicts[i]; len(problems) > 0 {
+			return fmt.Errorf("invalid config %q: %w", c.Name, errors.Join(problems...))
+		}
+	}
+
+	loggers := make([]*namedLogger, 0, len(cfg))
+	byName := make(map[string]*namedLogger, len(cfg))
+	// discard 初始化失败时关闭已创建的日志记录器的输出
+	discard := func() {
+		closed := make(map[io.Closer]bool)
+		for _, nl := range loggers {
+			reportSyncError(closeOutputs(nl.syncers, closed))
+		}
+	}
+	for _, c := range cfg {
+		nl, err := newNamedLogger(c, encCfg)
+		if err != nil {
+			discard()
+			return fmt.Errorf("invalid config %q: %w", c.Name, err)
+		}
+		loggers = append(loggers, nl)
+		byName[c.Name] = nl
+	}
+
+	// 确定合并到全局日志记录器中的日志记录器
+	global := loggers
+	if len(opts.GlobalCores) > 0 {
+		global = make([]*namedLogger, 0, len(opts.GlobalCores))
+		for _, name := range opts.GlobalCores {
+			nl, ok := byName[name]
+			if !ok {
+				discard()
+				return fmt.Errorf("invalid global core %q: %w", name, ErrLoggerNotFound)
 			}
+			global = append(global, nl)
+		}
+	}
 
-			L = newLogger(zapcore.NewTee(cores...))
-		} else {
-			// 如果没有配置日志记录器，默认使用控制台记录器
-			L = defaultLog
+	// 被替换的日志记录器和全局日志记录器的输出在替换后关闭
+	var replaced []syncer
+	if syncers := globalSyncers.Load(); syncers != nil {
+		replaced = append(replaced, *syncers...)
+	}
+	for _, name := range append([]string{DefaultLogName}, cfgNames(cfg)...) {
+		if nl, ok := loadLogger(name); ok {
+			replaced = append(replaced, nl.syncers...)
 		}
+	}
+	defer func() { reportSyncError(closeReplaced(replaced)) }()
 
-	})
+	// 创建默认日志记录器核心
+	defaultWS := zapcore.AddSync(os.Stdout)
+	defaultCounters := newLoggerCounters()
+	defaultLevel := zap.NewAtomicLevelAt(defaultLogLevel)
+	defaultLogCore := zapcore.NewCore(zapcore.NewConsoleEncoder(encCfg), defaultWS, defaultLevel)
+	defaultLog := newLogger(&statsCore{Core: defaultLogCore, counters: defaultCounters})
+	l.Store(DefaultLogName, &namedLogger{logger: defaultLog, syncers: []syncer{defaultWS}, counters: defaultCounters, level: defaultLevel})
+
+	for i, nl := range loggers {
+		l.Store(cfg[i].Name, nl)
+	}
+
+	if len(global) == 0 {
+		// 如果没有配置日志记录器，默认使用控制台记录器
+		setGlobalLogger(defaultLog, []syncer{defaultWS})
+		return nil
+	}
+
+	// 创建日志记录器核心
+	cores := make([]zapcore.Core, 0, len(global))
+	var syncers []syncer
+	for _, nl := range global {
+		cores = append(cores, nl.logger.Core())
+		syncers = append(syncers, nl.syncers...)
+	}
+	setGlobalLogger(newLogger(zapcore.NewTee(cores...)), syncers)
+	return nil
+}
+
+// cfgNames 返回配置中的日志记录器名称
+func cfgNames(cfg []Config) []string {
+	names := make([]string, 0, len(cfg))
+	for _, c := range cfg {
+		names = append(names, c.Name)
+	}
+	return names
+}
+
+// setGlobalLogger 替换全局日志记录器
+// syncers: 全局日志记录器的底层输出
+func setGlobalLogger(logger *zap.Logger, syncers []syncer) {
+	globalSyncers.Store(&syncers)
+	globalLogger.Store(logger)
+	L = logger
+}
+
+// With 为全局日志记录器永久添加字段，并发安全
+// 字段会经过合并到全局日志记录器中的各日志记录器自己的敏感数据过滤器处理
+// 应在初始化之后调用，Reinit会替换全局日志记录器并丢弃添加的字段
+func With(fields ...zapcore.Field) {
+	initMu.Lock()
+	defer initMu.Unlock()
+
+	replaceGlobalLogger(func(lg *zap.Logger) *zap.Logger { return lg.With(fields...) })
+}
+
+// Named 为全局日志记录器添加名称，并发安全
+// 应在初始化之后调用，Reinit会替换全局日志记录器并丢弃添加的名称
+func Named(name string) {
+	initMu.Lock()
+	defer initMu.Unlock()
+
+	replaceGlobalLogger(func(lg *zap.Logger) *zap.Logger { return lg.Named(name) })
+}
+
+// replaceGlobalLogger 基于当前全局日志记录器派生新的全局日志记录器，底层输出保持不变
+// 调用方需持有initMu
+func replaceGlobalLogger(derive func(lg *zap.Logger) *zap.Logger) {
+	var syncers []syncer
+	if current := globalSyncers.Load(); current != nil {
+		syncers = *current
+	}
+	setGlobalLogger(derive(GetGlobalLogger()), syncers)
+}
+
+// GetGlobalLogger 获取全局日志记录器，可与Reinit并发调用
+// 初始化前返回不输出任何内容的日志记录器
+func GetGlobalLogger() *zap.Logger {
+	if logger := globalLogger.Load(); logger != nil {
+		return logger
+	}
+	return nopLogger
+}
+
+// newNamedLogger 根据配置创建命名日志记录器，内置输出类型使用encCfg编码
+func newNamedLogger(cfg Config, encCfg zapcore.EncoderConfig) (*namedLogger, error) {
+	level, err := parseLoggerLevel(cfg.Level)
+	if err != nil {
+		return nil, err
+	}
+	atomicLevel := zap.NewAtomicLevelAt(level)
+
+	filter := newFilter(cfg)
+	counters := newLoggerCounters()
+	core, syncers, batch, err := buildCore(cfg, encCfg, filter, atomicLevel, counters)
+	if err != nil {
+		return nil, err
+	}
+	nl := &namedLogger{
+		logger:      newLogger(core),
+		filter:      filter,
+		syncers:     syncers,
+		batch:       batch,
+		idleTimeout: cfg.IdleTimeout,
+		counters:    counters,
+		level:       atomicLevel,
+	}
+	if cfg.IdleTimeout > 0 {
+		startIdleReaper()
+	}
+	return nl, nil
+}
+
+// newFilter 根据配置创建敏感数据过滤器
+// 未开启敏感数据过滤时返回nil
+func newFilter(cfg Config) *SensitiveDataFilter {
+	if !cfg.SensitiveFilter {
+		return nil
+	}
+	return NewSensitiveDataFilter(cfg.SensitiveFields)
 }
 
 // newCore 创建日志记录器核心
-// 如果日志记录器类型无效，会触发panic
-func newCore(cfg Config) zapcore.Core {
+// filter不为nil时使用敏感数据过滤编码器
+func newCore(cfg Config, filter *SensitiveDataFilter) (zapcore.Core, error) {
+	level, err := parseLoggerLevel(cfg.Level)
+	if err != nil {
+		return nil, err
+	}
+	core, _, _, err := buildCore(cfg, activeEncoderConfig(), filter, zap.NewAtomicLevelAt(level), nil)
+	return core, err
+}
+
+// buildCore 创建使用动态日志级别level的日志记录器核心，并按配置限制各级别日志条目的最小间隔
+// counters不为nil时统计实际写入输出的日志条目
+// 返回: 日志记录器核心，其底层输出，以及批量写入使用的输出（没有时为nil）
+func buildCore(cfg Config, encCfg zapcore.EncoderConfig, filter *SensitiveDataFilter, level zap.AtomicLevel, counters *loggerCounters) (zapcore.Core, []syncer, *batchWriteSyncer, error) {
+	core, syncers, batch, err := buildOutputCore(cfg, encCfg, filter, level, counters)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	if len(cfg.MinIntervalMs) > 0 {
+		if core, err = newCooldownCore(core, cfg.MinIntervalMs); err != nil {
+			return nil, nil, nil, err
+		}
+	}
+	return core, syncers, batch, nil
+}
+
+// buildOutputCore 根据输出类型创建日志记录器核心
+// 自定义类型的日志核心由CoreBuilder按配置的级别创建，level只能在此基础上进一步限制
+// 统计写入情况的statsCore直接包装输出核心，位于编码器与最终输出之间
+// 返回: 日志记录器核心，其底层输出，以及批量写入使用的输出（Splunk、NATS等自定义类型为nil）
+func buildOutputCore(cfg Config, encCfg zapcore.EncoderConfig, filter *SensitiveDataFilter, level zap.AtomicLevel, counters *loggerCounters) (zapcore.Core, []syncer, *batchWriteSyncer, error) {
+	timeEncoder, err := getTimeEncoder(cfg.TimeFormat)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	if cfg.TimeFormat != "" {
+		encCfg.EncodeTime = timeEncoder
+	}
+
 	var encoder zapcore.Encoder
 
-	// 未开启敏感数据过滤，根据日志记录器类型创建编码器
+	// 根据日志记录器类型创建内部编码器
 	switch cfg.Type {
 	case File:
-		encoder = zapcore.NewJSONEncoder(encoderConfig)
+		encoder = zapcore.NewJSONEncoder(encCfg)
+		if cfg.PrettyJSON {
+			if level.Level() > zapcore.DebugLevel {
+				fmt.Fprintf(os.Stderr, "zaploggerfilter: pretty JSON is not suitable for production, logger %q uses level %s\n", cfg.Name, level.Level())
+			}
+			encoder = &prettyJSONEncoder{Encoder: encoder, lineEnding: lineEnding(encCfg)}
+		}
+	case Splunk, NATS:
+		if _, ok := lookupEncoderCoreBuilder(cfg.Type); !ok {
+			return nil, nil, nil, errUnregisteredSink(cfg.Type)
+		}
+		encoder = zapcore.NewJSONEncoder(encCfg)
+	case LogfmtConsole, LogfmtFile:
+		encoder = NewLogfmtEncoder(encCfg)
 	case Console:
-		encoder = zapcore.NewConsoleEncoder(encoderConfig)
+		encoder = zapcore.NewConsoleEncoder(encCfg)
+		if cfg.ColorOutput && colorEnabled() {
+			encoder = &colorEncoder{Encoder: encoder, lineEnding: lineEnding(encCfg)}
+		}
 	default:
-		panic("unknown zap core type: " + cfg.Type)
+		if _, ok := lookupEncoderCoreBuilder(cfg.Type); ok {
+			encoder = zapcore.NewJSONEncoder(encCfg)
+			break
+		}
+		core, err := newCustomCore(cfg)
+		if err != nil {
+			return nil, nil, nil, err
+		}
+		output := &coreOutput{core: core}
+		if filter != nil {
+			core = &filteringCore{Core: core, filter: filter}
+		}
+		return &levelGatedCore{Core: newStatsCore(core, counters, true), level: level}, []syncer{output}, nil, nil
 	}
 
 	// 根据配置创建日志编码器
-	if cfg.SensitiveFilter {
+	if filter != nil {
 		// 开启敏感数据过滤，使用敏感数据过滤编码器
 		encoder = &SensitiveDataEncoder{
 			Encoder: encoder,
-			Filter:  NewSensitiveDataFilter(cfg.SensitiveFields),
+			Filter:  filter,
 		}
 	}
 
+	var ws zapcore.WriteSyncer
+	var syncers []syncer
 	switch cfg.Type {
-	case Console:
-		return zapcore.NewCore(zapcore.NewConsoleEncoder(encoderConfig), zapcore.AddSync(os.Stdout), getLoggerLevel(cfg.Level))
-	case File:
-		return zapcore.NewCore(
-			encoder,
-			zapcore.AddSync(&lumberjack.Logger{
-				Filename:   cfg.Path,
-				MaxSize:    cfg.MaxSize,
-				MaxBackups: cfg.MaxBackups,
-				MaxAge:     cfg.MaxAge,
-				Compress:   cfg.Compress,
-			}),
-			getLoggerLevel(cfg.Level),
-		)
+	case Console, LogfmtConsole:
+		ws = zapcore.AddSync(os.Stdout)
+		if cfg.WriteSyncer != nil {
+			ws = cfg.WriteSyncer
+		}
+		syncers = []syncer{ws}
+	case File, LogfmtFile:
+		if cfg.WriteSyncer != nil {
+			ws = cfg.WriteSyncer
+			syncers = []syncer{ws}
+			break
+		}
+		files := newFileSyncers(cfg)
+		ws = files[0]
+		if len(files) > 1 {
+			ws = zapcore.NewMultiWriteSyncer(files...)
+		}
+		for _, file := range files {
+			syncers = append(syncers, file)
+		}
 	default:
-		return nil
+		build, _ := lookupEncoderCoreBuilder(cfg.Type)
+		core, err := build(cfg, encoder, level)
+		if err != nil {
+			return nil, nil, nil, fmt.Errorf("failed to build %q core: %w", cfg.Type, err)
+		}
+		return newStatsCore(core, counters, false), []syncer{&coreOutput{core: core}}, nil, nil
+	}
+
+	batch := newBatchWriteSyncer(ws)
+	return newStatsCore(newEncoderCore(encoder, batch, level), counters, false), syncers, batch, nil
+}
+
+// lineEnding 返回编码器配置的行尾，未设置时与zap一致使用默认行尾
+func lineEnding(encCfg zapcore.EncoderConfig) string {
+	if encCfg.LineEnding == "" {
+		return zapcore.DefaultLineEnding
+	}
+	return encCfg.LineEnding
+}
+
+// newCustomCore 使用注册的CoreBuilder创建自定义类型的日志记录器核心
+func newCustomCore(cfg Config) (zapcore.Core, error) {
+	builder, ok := lookupCoreBuilder(cfg.Type)
+	if !ok {
+		return nil, fmt.Errorf("unknown zap core type: %q", cfg.Type)
+	}
+
+	core, err := builder(cfg)
+	if err != nil {
+		return nil, fmt.Errorf("failed to build %q core: %w", cfg.Type, err)
+	}
+	return core, nil
+}
+
+// newFileSyncers 创建文件日志输出，每个路径对应一个输出
+// 配置了多个路径时，同一条编码后的日志会写入所有文件
+func newFileSyncers(cfg Config) []zapcore.WriteSyncer {
+	paths := filePaths(cfg)
+	syncers := make([]zapcore.WriteSyncer, 0, len(paths))
+	for _, path := range paths {
+		syncers = append(syncers, &fileSyncer{newFileWriter(cfg, path)})
+	}
+	return syncers
+}
+
+// filePaths 获取文件日志记录器配置的所有文件路径，已去除重复路径
+func filePaths(cfg Config) []string {
+	paths := make([]string, 0, len(cfg.Paths)+1)
+	if cfg.Path != "" || len(cfg.Paths) == 0 {
+		paths = append(paths, cfg.Path)
+	}
+	for _, path := range cfg.Paths {
+		if !slices.Contains(paths, path) {
+			paths = append(paths, path)
+		}
+	}
+	return paths
+}
+
+// getTimeEncoder 根据时间格式获取时间编码器
+// 支持 rfc3339（默认）、rfc3339nano、unix、unixmilli、unixnano
+func getTimeEncoder(format string) (zapcore.TimeEncoder, error) {
+	switch format {
+	case "", "rfc3339":
+		return zapcore.RFC3339TimeEncoder, nil
+	case "rfc3339nano":
+		return zapcore.RFC3339NanoTimeEncoder, nil
+	case "unix":
+		return zapcore.EpochTimeEncoder, nil
+	case "unixmilli":
+		return zapcore.EpochMillisTimeEncoder, nil
+	case "unixnano":
+		return zapcore.EpochNanosTimeEncoder, nil
+	default:
+		return nil, fmt.Errorf("invalid time format: %q", format)
 	}
 }
 
-// getLoggerLevel 获取日志级别
-// 如果配置的日志级别无效，会触发panic
-func getLoggerLevel(level string) zapcore.Level {
+// parseLoggerLevel 解析日志级别
+func parseLoggerLevel(level string) (zapcore.Level, error) {
 	switch level {
 	case "debug":
-		return zap.DebugLevel
+		return zap.DebugLevel, nil
 	case "info":
-		return zap.InfoLevel
+		return zap.InfoLevel, nil
 	case "warn":
-		return zap.WarnLevel
+		return zap.WarnLevel, nil
 	case "error":
-		return zap.ErrorLevel
+		return zap.ErrorLevel, nil
 	case "panic":
-		return zap.PanicLevel
+		return zap.PanicLevel, nil
 	case "fatal":
-		return zap.FatalLevel
+		return zap.FatalLevel, nil
 	default:
-		panic("invalid log level")
+		levelAliasMu.RLock()
+		lvl, ok := levelAliases[level]
+		levelAliasMu.RUnlock()
+		if ok {
+			return lvl, nil
+		}
+		return zapcore.InvalidLevel, fmt.Errorf("invalid log level: %q", level)
 	}
 }
 
+var (
+	// levelAliases 日志级别别名
+	levelAliases = map[string]zapcore.Level{
+		"trace":    zap.DebugLevel,
+		"notice":   zap.InfoLevel,
+		"critical": zap.ErrorLevel,
+		"alert":    zap.ErrorLevel,
+	}
+	levelAliasMu sync.RWMutex
+)
+
+// RegisterLevelAlias 注册日志级别别名，之后可以在Config.Level中使用该别名
+// 内置别名：trace（debug）、notice（info）、critical（error）、alert（error）
+// 别名在创建日志记录器时解析，需要在Init等初始化函数之前注册；内置级别名称不能被覆盖
+func RegisterLevelAlias(alias string, level zapcore.Level) {
+	levelAliasMu.Lock()
+	defer levelAliasMu.Unlock()
+
+	levelAliases[alias] = level
+}
+
 // newLogger 创建日志记录器
 func newLogger(core zapcore.Core, options ...zap.Option) *zap.Logger {
 	options = append(options, zap.AddCaller())
 	return zap.New(core, options...)
 }
 
-// AddTargetLogger 添加目标日志记录器
+// AddTargetLogger 添加目标日志记录器，替换同名的日志记录器时关闭其不再使用的输出
+// 如果配置无效，会触发panic
 func AddTargetLogger(c Config) {
-	core := newCore(c)
+	nl, err := newNamedLogger(c, activeEncoderConfig())
+	if err != nil {
+		panic(err)
+	}
 
-	l.Store(c.Name, newLogger(core))
+	initMu.Lock()
+	defer initMu.Unlock()
+
+	if old, ok := l.Swap(c.Name, nl); ok {
+		reportSyncError(closeReplaced(old.(*namedLogger).syncers))
+	}
+}
+
+// loadLogger 从日志记录器映射中获取命名日志记录器
+func loadLogger(target string) (*namedLogger, bool) {
+	v, ok := l.Load(target)
+	if ok {
+		return v.(*namedLogger), true
+	}
+	return nil, false
 }
 
 // GetTargetLogger 获取目标日志记录器
+// 目标不存在时返回不输出任何内容的日志记录器和false，返回的日志记录器始终不为nil
 func GetTargetLogger(target string) (*zap.Logger, bool) {
-	lg, ok := l.Load(target)
+	nl, ok := loadLogger(target)
+	if ok {
+		return nl.logger, true
+	}
+	return nopLogger, false
+}
+
+// GetTargetLoggerStrict 获取目标日志记录器，目标不存在时返回nil和false
+func GetTargetLoggerStrict(target string) (*zap.Logger, bool) {
+	nl, ok := loadLogger(target)
 	if ok {
-		return lg.(*zap.Logger), true
+		return nl.logger, true
 	}
 	return nil, false
 }
 
+// WithFields 基于目标日志记录器创建带有固定字段的新日志记录器，并存储在newName下
+// 新日志记录器沿用目标的日志核心，因此继承目标的敏感数据过滤器，固定字段同样会经过该过滤器处理
+func WithFields(target, newName string, fields ...zapcore.Field) error {
+	nl, ok := loadLogger(target)
+	if !ok {
+		return fmt.Errorf("%w: %s", ErrLoggerNotFound, target)
+	}
+
+	l.Store(newName, &namedLogger{
+		logger:    nl.logger.With(fields...),
+		filter:    nl.filter,
+		syncers:   nl.syncers,
+		batch:     nl.batch,
+		counters:  nl.counters,
+		level:     nl.level,
+		derived:   true,
+		namespace: fieldsNamespace(nl.namespace, fields),
+	})
+	return nil
+}
+
+// WithFieldsFilter 与WithFields相同，但新日志记录器使用filter代替目标的敏感数据过滤器
+// 固定字段和之后记录的字段都会经过filter处理；目标日志核心的编码器仍会使用目标的过滤器，
+// 因此filter只能在目标过滤器的基础上增加需要掩码的字段。filter为nil时与WithFields相同
+func WithFieldsFilter(target, newName string, filter *SensitiveDataFilter, fields ...zapcore.Field) error {
+	if filter == nil {
+		return WithFields(target, newName, fields...)
+	}
+
+	nl, ok := loadLogger(target)
+	if !ok {
+		return fmt.Errorf("%w: %s", ErrLoggerNotFound, target)
+	}
+
+	core := &filteringCore{Core: nl.logger.Core(), filter: filter, namespace: nl.namespace}
+	l.Store(newName, &namedLogger{
+		logger:    newLogger(core.With(fields)),
+		filter:    filter,
+		syncers:   nl.syncers,
+		batch:     nl.batch,
+		counters:  nl.counters,
+		level:     nl.level,
+		derived:   true,
+		namespace: fieldsNamespace(nl.namespace, fields),
+	})
+	return nil
+}
+
 // DebugTo 向指定目标记录调试级别的日志
-func DebugTo(target string, msg string, fields ...zapcore.Field) {
-	LogTo(target, zapcore.DebugLevel, msg, fields...)
+// 返回: 目标日志记录器是否存在
+func DebugTo(target string, msg string, fields ...zapcore.Field) bool {
+	return LogTo(target, zapcore.DebugLevel, msg, fields...)
 }
 
 // InfoTo 向指定目标记录信息级别的日志
-func InfoTo(target string, msg string, fields ...zapcore.Field) {
-	LogTo(target, zapcore.InfoLevel, msg, fields...)
+// 返回: 目标日志记录器是否存在
+func InfoTo(target string, msg string, fields ...zapcore.Field) bool {
+	return LogTo(target, zapcore.InfoLevel, msg, fields...)
 }
 
 // WarnTo 向指定目标记录警告级别的日志
-func WarnTo(target string, msg string, fields ...zapcore.Field) {
-	LogTo(target, zapcore.WarnLevel, msg, fields...)
+// 返回: 目标日志记录器是否存在
+func WarnTo(target string, msg string, fields ...zapcore.Field) bool {
+	return LogTo(target, zapcore.WarnLevel, msg, fields...)
 }
 
 // ErrorTo 向指定目标记录错误级别的日志
-func ErrorTo(target string, msg string, fields ...zapcore.Field) {
-	LogTo(target, zapcore.ErrorLevel, msg, fields...)
+// 返回: 目标日志记录器是否存在
+func ErrorTo(target string, msg string, fields ...zapcore.Field) bool {
+	return LogTo(target, zapcore.ErrorLevel, msg, fields...)
+}
+
+// DPanicTo 向指定目标记录dpanic级别的日志，开发模式下记录后触发panic
+func DPanicTo(target string, msg string, fields ...zapcore.Field) {
+	if lg, _ := targetLogger(target); lg != nil {
+		lg.DPanic(msg, fields...)
+	}
+}
+
+// PanicTo 向指定目标记录panic级别的日志，记录后触发panic
+func PanicTo(target string, msg string, fields ...zapcore.Field) {
+	if lg, _ := targetLogger(target); lg != nil {
+		lg.Panic(msg, fields...)
+	}
+}
+
+// FatalTo 向指定目标记录fatal级别的日志，记录后退出进程
+func FatalTo(target string, msg string, fields ...zapcore.Field) {
+	if lg, _ := targetLogger(target); lg != nil {
+		lg.Fatal(msg, fields...)
+	}
 }
 
 // LogTo 向指定目标记录日志
-func LogTo(target string, lvl zapcore.Level, msg string, fields ...zapcore.Field) {
-	v, ok := l.Load(target)
-	if ok {
-		v.(*zap.Logger).Log(lvl, msg, fields...)
+// 返回: 目标日志记录器是否存在，不存在时按SetMissingLoggerPolicy设置的方式处理（默认不做任何处理）
+func LogTo(target string, lvl zapcore.Level, msg string, fields ...zapcore.Field) bool {
+	lg, ok := targetLogger(target)
+	if lg != nil {
+		lg.Log(lvl, msg, fields...)
+	}
+	return ok
+}
+
+// LogToWithTimeout 向指定目标记录日志，最多等待timeout
+// 返回: 在超时前写入完成则返回true，超时返回false（写入会在后台继续，条目仍可能被写入）
+func LogToWithTimeout(target string, timeout time.Duration, lvl zapcore.Level, msg string, fields ...zapcore.Field) bool {
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		LogTo(target, lvl, msg, fields...)
+	}()
+
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+
+	select {
+	case <-done:
+		return true
+	case <-timer.C:
+		return false
+	}
+}
+
+// LogIf 当cond为true时向指定目标记录日志
+// cond为false或目标日志记录器的级别高于lvl时不做任何处理
+func LogIf(target string, cond bool, lvl zapcore.Level, msg string, fields ...zapcore.Field) {
+	if !cond {
+		return
+	}
+	LogTo(target, lvl, msg, fields...)
+}
+
+// LogIfEnabled 当目标日志记录器启用了lvl级别时向其记录日志
+// 字段由fieldsFunc延迟构建，级别未启用时不会调用，适用于构建代价较高的字段（如zap.Reflect）
+func LogIfEnabled(target string, lvl zapcore.Level, msg string, fieldsFunc func() []zapcore.Field) {
+	nl, ok := loadLogger(target)
+	if !ok {
+		return
+	}
+	if ce := nl.logger.Check(lvl, msg); ce != nil {
+		var fields []zapcore.Field
+		if fieldsFunc != nil {
+			fields = fieldsFunc()
+		}
+		ce.Write(fields...)
 	}
 }
 
 // Sync 同步日志记录器
 func Sync() {
-	_ = L.Sync()
+	_ = syncAll()
+}
+
+// SyncLogger 同步指定的日志记录器，如在RotateLogger之前刷新其输出
+// 名称为DefaultLogName时同步内部的默认日志记录器
+// 返回: 目标不存在时返回ErrLoggerNotFound；同步错误，忽略标准输出等不支持同步的输出产生的错误
+func SyncLogger(name string) error {
+	nl, ok := loadLogger(name)
+	if !ok {
+		return fmt.Errorf("%w: %s", ErrLoggerNotFound, name)
+	}
+	return syncNamedLogger(fmt.Sprintf("logger %q", name), nl)
+}
 
-	l.Range(func(_, v interface{}) bool {
-		_ = v.(*zap.Logger).Sync()
+// syncAll 同步全局日志记录器和所有命名日志记录器
+// 多个日志记录器共享的底层输出只会同步一次，每个底层输出最多返回一个错误
+// 返回: 所有同步错误，忽略标准输出等不支持同步的输出产生的错误
+func syncAll() error {
+	var errs []error
+	seen := make(map[syncer]bool)
+	syncOnce := func(name string, s syncer) {
+		if reflect.TypeOf(s).Comparable() {
+			if seen[s] {
+				return
+			}
+			seen[s] = true
+		}
+		if err := s.Sync(); err != nil && !isIgnorableSyncError(err) {
+			errs = append(errs, fmt.Errorf("failed to sync %s: %w", name, err))
+		}
+	}
+
+	if syncers := globalSyncers.Load(); syncers != nil {
+		for _, s := range *syncers {
+			syncOnce("global logger", s)
+		}
+	}
+
+	l.Range(func(k, v interface{}) bool {
+		nl := v.(*namedLogger)
+		name := fmt.Sprintf("logger %q", k)
+		if nl.syncers == nil {
+			syncOnce(name, nl.logger)
+			return true
+		}
+		for _, s := range nl.syncers {
+			syncOnce(name, s)
+		}
 		return true
 	})
+	return errors.Join(errs...)
+}
+
+// isIgnorableSyncError 判断是否为终端、管道等不支持同步的输出产生的错误
+func isIgnorableSyncError(err error) bool {
+	return errors.Is(err, syscall.EINVAL) || errors.Is(err, syscall.ENOTTY)
 }
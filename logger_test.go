@@ -0,0 +1,269 @@
+package zaploggerfilter
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"testing"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+func TestPanicTo(t *testing.T) {
+	var buf syncBuffer
+	initTestLoggers(t, bufferConfig("app", &buf, "password"))
+
+	func() {
+		defer func() {
+			r := recover()
+			if r == nil {
+				t.Fatal("PanicTo did not panic")
+			}
+			if r != "panic entry" {
+				t.Errorf("panic = %v, want the log message", r)
+			}
+		}()
+		PanicTo("app", "panic entry", zap.String("password", "secret"))
+	}()
+
+	// 记录后才触发panic，字段同样经过过滤
+	got := buf.String()
+	if !strings.Contains(got, "panic entry") || !strings.Contains(got, `"password": "***"`) {
+		t.Errorf("output = %q, want masked panic entry", got)
+	}
+}
+
+func TestPanicToMissingTarget(t *testing.T) {
+	initTestLoggers(t)
+
+	defer func() {
+		if r := recover(); r != nil {
+			t.Errorf("PanicTo panicked for a missing target with DropEntry policy: %v", r)
+		}
+	}()
+	PanicTo("missing", "dropped")
+}
+
+// recordingHook 记录Fatal级别日志条目的CheckWriteHook，代替退出进程
+type recordingHook struct {
+	entries []zapcore.Entry
+}
+
+// OnWrite 实现zapcore.CheckWriteHook接口
+func (h *recordingHook) OnWrite(ce *zapcore.CheckedEntry, _ []zapcore.Field) {
+	h.entries = append(h.entries, ce.Entry)
+}
+
+func TestFatalTo(t *testing.T) {
+	var buf syncBuffer
+	initTestLoggers(t, bufferConfig("app", &buf, "password"))
+
+	// 替换为使用记录钩子的日志记录器，保留原日志核心
+	nl, _ := loadLogger("app")
+	hook := &recordingHook{}
+	l.Store("fatal", &namedLogger{logger: nl.logger.WithOptions(zap.WithFatalHook(hook)), filter: nl.filter})
+
+	FatalTo("fatal", "fatal entry", zap.String("password", "secret"))
+
+	if len(hook.entries) != 1 {
+		t.Fatalf("fatal hook called %d times, want 1", len(hook.entries))
+	}
+	if ent := hook.entries[0]; ent.Level != zapcore.FatalLevel || ent.Message != "fatal entry" {
+		t.Errorf("fatal hook entry = %+v, want fatal level entry", ent)
+	}
+	if got := buf.String(); !strings.Contains(got, "fatal entry") || !strings.Contains(got, `"password": "***"`) {
+		t.Errorf("output = %q, want masked fatal entry", got)
+	}
+}
+
+func TestNamedLoggersConcurrent(t *testing.T) {
+	var buf syncBuffer
+	cfg := []Config{bufferConfig("app", &buf, "password")}
+	initTestLoggers(t, cfg...)
+
+	const workers, iterations = 4, 100
+	var wg sync.WaitGroup
+	run := func(fn func(i, j int)) {
+		for i := 0; i < workers; i++ {
+			wg.Add(1)
+			go func(i int) {
+				defer wg.Done()
+				for j := 0; j < iterations; j++ {
+					fn(i, j)
+				}
+			}(i)
+		}
+	}
+
+	// 日志记录器会在记录日志的同时被替换、派生和修改级别
+	run(func(_, j int) {
+		if j%10 == 0 {
+			Init(cfg)
+		}
+	})
+	run(func(_, _ int) {
+		LogTo("app", zapcore.InfoLevel, "login", zap.String("password", "secret"))
+		GetGlobalLogger().Info("global", zap.String("password", "secret"))
+	})
+	run(func(i, j int) {
+		name := fmt.Sprintf("app.worker%d", i)
+		if err := WithFields("app", name, zap.Int("iteration", j)); err != nil {
+			t.Errorf("WithFields() error = %v", err)
+		}
+		InfoTo(name, "derived", zap.String("password", "secret"))
+		if err := RegisterNamespace("app", fmt.Sprintf("ns%d", i)); err != nil {
+			t.Errorf("RegisterNamespace() error = %v", err)
+		}
+	})
+	run(func(_, j int) {
+		level := "debug"
+		if j%2 == 0 {
+			level = "warn"
+		}
+		_ = SetLevel("app", level)
+		GetLevel("app")
+		GetLoggerStats("app")
+	})
+	run(func(_, _ int) {
+		Sync()
+		_ = SyncLogger("app")
+		if lg, ok := GetTargetLogger("app"); ok {
+			lg.Debug("target", zap.String("password", "secret"))
+		}
+	})
+	wg.Wait()
+
+	if got := buf.String(); strings.Contains(got, "secret") {
+		t.Error("sensitive value written during concurrent use")
+	}
+	if err := SyncLogger("app"); err != nil {
+		t.Errorf("SyncLogger() error = %v", err)
+	}
+}
+
+// closerCore 记录关闭次数的日志核心
+type closerCore struct {
+	zapcore.Core
+	closed *int
+}
+
+// Close 实现io.Closer接口
+func (c *closerCore) Close() error {
+	*c.closed++
+	return nil
+}
+
+// registerCloserCore 注册创建closerCore的类型，返回每个配置名称对应核心的关闭次数
+func registerCloserCore(t ZapCoreType) map[string]*int {
+	closed := make(map[string]*int)
+	RegisterEncoderCoreBuilder(t, func(cfg Config, enc zapcore.Encoder, level zapcore.LevelEnabler) (zapcore.Core, error) {
+		n := new(int)
+		closed[cfg.Name] = n
+		return &closerCore{Core: zapcore.NewCore(enc, zapcore.AddSync(new(syncBuffer)), level), closed: n}, nil
+	})
+	return closed
+}
+
+func TestResetClosesCores(t *testing.T) {
+	closed := registerCloserCore("closer-reset")
+	initTestLoggers(t, Config{Type: "closer-reset", Name: "remote", Level: "info"})
+	WithFields("remote", "remote.child", zap.String("k", "v"))
+
+	if err := Reset(); err != nil {
+		t.Fatalf("Reset() error = %v", err)
+	}
+	// 全局日志记录器和派生日志记录器共享同一个核心，只关闭一次
+	if got := *closed["remote"]; got != 1 {
+		t.Errorf("core closed %d times after Reset, want 1", got)
+	}
+}
+
+func TestReinitClosesReplacedCores(t *testing.T) {
+	closed := registerCloserCore("closer-reinit")
+	initTestLoggers(t,
+		Config{Type: "closer-reinit", Name: "kept", Level: "info"},
+		Config{Type: "closer-reinit", Name: "replaced", Level: "info"},
+	)
+	first := map[string]*int{"kept": closed["kept"], "replaced": closed["replaced"]}
+	WithFields("kept", "kept.child", zap.String("k", "v"))
+
+	if err := Reinit([]Config{{Type: "closer-reinit", Name: "replaced", Level: "info"}}); err != nil {
+		t.Fatalf("Reinit() error = %v", err)
+	}
+	if got := *first["replaced"]; got != 1 {
+		t.Errorf("replaced core closed %d times, want 1", got)
+	}
+	// 未被替换的日志记录器仍在使用其核心
+	if got := *first["kept"]; got != 0 {
+		t.Errorf("kept core closed %d times, want 0", got)
+	}
+
+	// 全局日志记录器未使用的同名日志记录器被AddTargetLogger替换时关闭
+	AddTargetLogger(Config{Type: "closer-reinit", Name: "extra", Level: "info"})
+	extra := closed["extra"]
+	AddTargetLogger(Config{Type: "closer-reinit", Name: "extra", Level: "info"})
+	if got := *extra; got != 1 {
+		t.Errorf("core replaced by AddTargetLogger closed %d times, want 1", got)
+	}
+}
+
+func TestInitWithOptionsDefaultsPerInit(t *testing.T) {
+	t.Cleanup(func() { _ = Reset() })
+	prevMessageKey, prevLevel := encoderConfig.MessageKey, DefaultLogLevel
+	ec := zap.NewProductionEncoderConfig()
+	ec.MessageKey = "message"
+
+	var buf syncBuffer
+	cfg := bufferConfig("app", &buf)
+	cfg.Type = File
+	err := InitWithOptions(
+		WithConfigs([]Config{cfg}),
+		WithEncoderConfig(ec),
+		WithDefaultLevel(zapcore.WarnLevel),
+	)
+	if err != nil {
+		t.Fatalf("InitWithOptions() error = %v", err)
+	}
+	// 选项只对本次初始化生效，不修改包级默认值
+	if DefaultLogLevel != prevLevel || encoderConfig.MessageKey != prevMessageKey {
+		t.Errorf("InitWithOptions changed DefaultLogLevel or encoderConfig")
+	}
+	if nl, _ := loadLogger(DefaultLogName); nl.level.Level() != zapcore.WarnLevel {
+		t.Errorf("default logger level = %s, want warn", nl.level.Level())
+	}
+	InfoTo("app", "hello")
+	if got := buf.String(); !strings.Contains(got, `"message":"hello"`) {
+		t.Errorf("output = %q, want the configured message key", got)
+	}
+
+	if err := InitWithOptions(WithConfigs([]Config{cfg})); !errors.Is(err, ErrAlreadyInitialized) {
+		t.Errorf("second InitWithOptions() error = %v, want ErrAlreadyInitialized", err)
+	}
+
+	// 再次初始化未设置编码器配置时使用库默认配置
+	if err := Reinit([]Config{cfg}); err != nil {
+		t.Fatalf("Reinit() error = %v", err)
+	}
+	InfoTo("app", "again")
+	if got := buf.String(); !strings.Contains(got, `"`+prevMessageKey+`":"again"`) {
+		t.Errorf("output after Reinit = %q, want the default message key", got)
+	}
+}
+
+func TestResetRestoresInitDefaults(t *testing.T) {
+	t.Cleanup(func() { _ = Reset() })
+	ec := zap.NewProductionEncoderConfig()
+	ec.MessageKey = "message"
+	if err := InitWithOptions(WithEncoderConfig(ec), WithDefaultLevel(zapcore.WarnLevel)); err != nil {
+		t.Fatalf("InitWithOptions() error = %v", err)
+	}
+
+	if err := Reset(); err != nil {
+		t.Fatalf("Reset() error = %v", err)
+	}
+	if got := activeEncoderConfig(); got.MessageKey != encoderConfig.MessageKey {
+		t.Errorf("encoder config after Reset uses message key %q, want %q", got.MessageKey, encoderConfig.MessageKey)
+	}
+}
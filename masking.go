@@ -0,0 +1,249 @@
+package zaploggerfilter
+
+import (
+	"fmt"
+	"net/netip"
+	"strconv"
+	"strings"
+)
+
+// MaskingAlgorithm 掩码算法，用于对字段值进行格式保留等自定义掩码处理
+type MaskingAlgorithm interface {
+	// Mask 对值进行掩码处理
+	// 不符合算法格式要求的值应原样返回
+	Mask(value string) string
+}
+
+// CreditCardMask 信用卡号掩码算法
+// 保留前BINDigits位和后TrailDigits位数字，其余数字替换为*，分隔符保持不变
+// 例如 CreditCardMask{BINDigits: 6, TrailDigits: 4} 将 "4111111111111111" 处理为 "411111******1111"
+type CreditCardMask struct {
+	BINDigits   int
+	TrailDigits int
+}
+
+// Mask 实现MaskingAlgorithm接口
+// 未通过Luhn校验的值原样返回
+func (m CreditCardMask) Mask(value string) string {
+	digits := 0
+	for _, r := range value {
+		switch {
+		case r >= '0' && r <= '9':
+			digits++
+		case r == ' ' || r == '-':
+		default:
+			return value
+		}
+	}
+	if digits < 13 || digits > 19 || !luhnValid(value) {
+		return value
+	}
+
+	bin, trail := max(m.BINDigits, 0), max(m.TrailDigits, 0)
+	// 保留位数过多时全部掩码，避免完整卡号泄露
+	if bin+trail >= digits {
+		bin, trail = 0, 0
+	}
+
+	var sb strings.Builder
+	sb.Grow(len(value))
+	index := 0
+	for _, r := range value {
+		if r < '0' || r > '9' {
+			sb.WriteRune(r)
+			continue
+		}
+		if index < bin || index >= digits-trail {
+			sb.WriteRune(r)
+		} else {
+			sb.WriteByte('*')
+		}
+		index++
+	}
+	return sb.String()
+}
+
+// luhnValid 使用Luhn算法校验数字串，非数字字符会被忽略
+func luhnValid(value string) bool {
+	sum := 0
+	double := false
+	for i := len(value) - 1; i >= 0; i-- {
+		c := value[i]
+		if c < '0' || c > '9' {
+			continue
+		}
+		d := int(c - '0')
+		if double {
+			d *= 2
+			if d > 9 {
+				d -= 9
+			}
+		}
+		sum += d
+		double = !double
+	}
+	return sum%10 == 0
+}
+
+// maxMaskRunes MaskConfig中间掩码部分的最大长度，避免过长的值产生过长的掩码
+const maxMaskRunes = 16
+
+// MaskConfig 部分掩码算法，保留字符串值的前Prefix个和后Suffix个字符，中间部分替换为MaskChar
+// 中间部分的长度与原值相同，最多为16个字符；MaskChar为0时使用*
+// 例如 MaskConfig{Prefix: 2, Suffix: 2} 将 "secret-token" 处理为 "se********en"
+// Prefix和Suffix均为0，或值的长度不超过Prefix+Suffix时，值不会被部分保留：
+// 前者返回Mask，后者将整个值替换为MaskChar，避免完整的值泄露
+type MaskConfig struct {
+	Prefix   int
+	Suffix   int
+	MaskChar rune
+}
+
+// Mask 实现MaskingAlgorithm接口
+func (m MaskConfig) Mask(value string) string {
+	prefix, suffix := max(m.Prefix, 0), max(m.Suffix, 0)
+	if prefix == 0 && suffix == 0 {
+		return Mask
+	}
+	maskChar := m.MaskChar
+	if maskChar == 0 {
+		maskChar = '*'
+	}
+
+	runes := []rune(value)
+	if prefix+suffix >= len(runes) {
+		return strings.Repeat(string(maskChar), min(len(runes), maxMaskRunes))
+	}
+
+	var sb strings.Builder
+	sb.Grow(len(value))
+	sb.WriteString(string(runes[:prefix]))
+	sb.WriteString(strings.Repeat(string(maskChar), min(len(runes)-prefix-suffix, maxMaskRunes)))
+	sb.WriteString(string(runes[len(runes)-suffix:]))
+	return sb.String()
+}
+
+// SetMaskConfig 使用部分掩码作为过滤器默认的掩码算法，见MaskConfig
+// Prefix和Suffix均为0时清除默认的掩码算法，恢复使用掩码函数（默认返回Mask）；
+// 非字符串的敏感字段值不受影响，仍按掩码函数完全替换
+func (f *SensitiveDataFilter) SetMaskConfig(mc MaskConfig) {
+	if mc.Prefix <= 0 && mc.Suffix <= 0 {
+		f.SetMaskAlgorithm(nil)
+		return
+	}
+	f.SetMaskAlgorithm(mc)
+}
+
+// EmailMask 邮箱地址掩码算法
+// 将本地部分（@之前的内容）替换为***，保留域名，例如 "***@example.com"
+type EmailMask struct{}
+
+// Mask 实现MaskingAlgorithm接口
+// 不是邮箱地址格式的值原样返回
+func (EmailMask) Mask(value string) string {
+	// 带引号的本地部分中可能包含@，以最后一个@作为分隔
+	at := strings.LastIndexByte(value, '@')
+	if at <= 0 || at == len(value)-1 {
+		return value
+	}
+	domain := value[at+1:]
+	if strings.ContainsAny(domain, " \t\r\n\"@") {
+		return value
+	}
+	return "***@" + domain
+}
+
+// IPv4Mask IPv4地址掩码算法
+// 将地址的后OctetsToMask段替换为***，例如 IPv4Mask{OctetsToMask: 1} 将 "192.168.1.100" 处理为 "192.168.1.***"
+// 对于CIDR格式（如 "10.1.2.0/16"）仅掩码主机部分，网络前缀保持不变
+type IPv4Mask struct {
+	OctetsToMask int
+}
+
+// Mask 实现MaskingAlgorithm接口
+// 不是IPv4地址的值原样返回
+func (m IPv4Mask) Mask(value string) string {
+	addr, bits, ok := parseIPWithPrefix(value)
+	if !ok || !addr.Is4() {
+		return value
+	}
+
+	count := min(max(m.OctetsToMask, 0), 4)
+	if bits >= 0 {
+		// 仅掩码主机部分所在的段
+		count = min(count, (32-bits+7)/8)
+	}
+
+	octets := addr.As4()
+	parts := make([]string, len(octets))
+	for i, octet := range octets {
+		if i >= len(octets)-count {
+			parts[i] = Mask
+		} else {
+			parts[i] = strconv.Itoa(int(octet))
+		}
+	}
+	return formatIPWithPrefix(strings.Join(parts, "."), "", bits)
+}
+
+// IPv6Mask IPv6地址掩码算法
+// 将地址的后GroupsToMask组替换为***，例如 IPv6Mask{GroupsToMask: 4} 将 "2001:db8::1" 处理为 "2001:db8:0:0:***:***:***:***"
+// 对于CIDR格式仅掩码主机部分，网络前缀保持不变
+type IPv6Mask struct {
+	GroupsToMask int
+}
+
+// Mask 实现MaskingAlgorithm接口
+// 不是IPv6地址的值原样返回
+func (m IPv6Mask) Mask(value string) string {
+	addr, bits, ok := parseIPWithPrefix(value)
+	if !ok || !addr.Is6() {
+		return value
+	}
+
+	count := min(max(m.GroupsToMask, 0), 8)
+	if bits >= 0 {
+		// 仅掩码主机部分所在的组
+		count = min(count, (128-bits+15)/16)
+	}
+
+	bytes := addr.As16()
+	parts := make([]string, 8)
+	for i := range parts {
+		if i >= len(parts)-count {
+			parts[i] = Mask
+		} else {
+			parts[i] = strconv.FormatUint(uint64(bytes[2*i])<<8|uint64(bytes[2*i+1]), 16)
+		}
+	}
+	return formatIPWithPrefix(strings.Join(parts, ":"), addr.Zone(), bits)
+}
+
+// parseIPWithPrefix 解析IP地址，支持CIDR格式
+// 返回: 地址、前缀长度（非CIDR格式时为-1）、是否解析成功
+func parseIPWithPrefix(value string) (netip.Addr, int, bool) {
+	if strings.Contains(value, "/") {
+		prefix, err := netip.ParsePrefix(value)
+		if err != nil {
+			return netip.Addr{}, 0, false
+		}
+		return prefix.Addr(), prefix.Bits(), true
+	}
+
+	addr, err := netip.ParseAddr(value)
+	if err != nil {
+		return netip.Addr{}, 0, false
+	}
+	return addr, -1, true
+}
+
+// formatIPWithPrefix 拼接掩码后的地址、区域和前缀长度
+func formatIPWithPrefix(addr, zone string, bits int) string {
+	if zone != "" {
+		addr += "%" + zone
+	}
+	if bits >= 0 {
+		addr = fmt.Sprintf("%s/%d", addr, bits)
+	}
+	return addr
+}
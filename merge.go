@@ -0,0 +1,176 @@
+package zaploggerfilter
+
+import (
+	"fmt"
+	"os"
+	"reflect"
+)
+
+// Merge 合并两个过滤器，返回包含两者字段、字段名模式和字段值模式并集的新过滤器
+// 两个过滤器的字段级别配置冲突时以接收者为准；新过滤器使用接收者的掩码算法，
+// other的掩码算法会被忽略，并在两者不一致时输出警告到标准错误
+func (f *SensitiveDataFilter) Merge(other *SensitiveDataFilter) *SensitiveDataFilter {
+	if other == nil {
+		return f.Clone()
+	}
+
+	// 先复制other，避免同时持有两个过滤器的锁
+	src := other.Clone()
+	merged := f.Clone()
+
+	for field := range src.sensitiveFields {
+		merged.sensitiveFields[field] = true
+	}
+	for _, re := range src.fieldPatterns {
+		merged.addFieldPattern(re)
+	}
+	for _, vp := range src.valuePatterns {
+		if !merged.hasValuePattern(vp) {
+			merged.valuePatterns = append(merged.valuePatterns, vp)
+		}
+	}
+	for field, mask := range src.fieldMasks {
+		if _, ok := merged.fieldMasks[field]; !ok {
+			if merged.fieldMasks == nil {
+				merged.fieldMasks = make(map[string]string, len(src.fieldMasks))
+			}
+			merged.fieldMasks[field] = mask
+		}
+	}
+	for name, g := range src.groups {
+		if _, ok := merged.groups[name]; !ok {
+			if merged.groups == nil {
+				merged.groups = make(map[string]*fieldGroup, len(src.groups))
+			}
+			merged.groups[name] = g
+		}
+	}
+	for internal, external := range src.aliases {
+		if _, ok := merged.aliases[internal]; !ok {
+			if merged.aliases == nil {
+				merged.aliases = make(map[string]string, len(src.aliases))
+			}
+			merged.aliases[internal] = external
+		}
+	}
+	for field, algorithm := range src.fieldAlgorithms {
+		if _, ok := merged.fieldAlgorithms[field]; !ok {
+			if merged.fieldAlgorithms == nil {
+				merged.fieldAlgorithms = make(map[string]MaskingAlgorithm, len(src.fieldAlgorithms))
+			}
+			merged.fieldAlgorithms[field] = algorithm
+		}
+	}
+	for field, fn := range src.fieldMaskFuncs {
+		if _, ok := merged.fieldMaskFuncs[field]; !ok {
+			if merged.fieldMaskFuncs == nil {
+				merged.fieldMaskFuncs = make(map[string]FieldMaskFunc, len(src.fieldMaskFuncs))
+			}
+			merged.fieldMaskFuncs[field] = fn
+		}
+	}
+
+	if src.maskAlgorithm != nil && !reflect.DeepEqual(src.maskAlgorithm, merged.maskAlgorithm) {
+		fmt.Fprintf(os.Stderr, "zaploggerfilter: merge ignores mask algorithm %T of the argument filter\n", src.maskAlgorithm)
+	}
+
+	return merged
+}
+
+// Intersection 返回只包含两个过滤器共有的字段、字段名模式和字段值模式的新过滤器
+// 适用于白名单场景，字段级别配置和掩码算法以接收者为准
+func (f *SensitiveDataFilter) Intersection(other *SensitiveDataFilter) *SensitiveDataFilter {
+	if other == nil {
+		return NewSensitiveDataFilter(nil)
+	}
+
+	src := other.Clone()
+	base := f.Clone()
+
+	result := NewSensitiveDataFilter(nil)
+	result.maskAlgorithm = base.maskAlgorithm
+	result.maskFunc = base.maskFunc
+	result.debugLogger.Store(base.debugLogger.Load())
+	result.disabled.Store(base.disabled.Load())
+	result.preserveTypes = base.preserveTypes
+	result.omitZeroValues = base.omitZeroValues
+	result.messageMasking = base.messageMasking
+	result.auditTrail = base.auditTrail
+	result.auditTrailKey = base.auditTrailKey
+	for field := range base.sensitiveFields {
+		if !src.sensitiveFields[field] {
+			continue
+		}
+		result.sensitiveFields[field] = true
+		if mask, ok := base.fieldMasks[field]; ok {
+			if result.fieldMasks == nil {
+				result.fieldMasks = make(map[string]string)
+			}
+			result.fieldMasks[field] = mask
+		}
+		if algorithm, ok := base.fieldAlgorithms[field]; ok {
+			if result.fieldAlgorithms == nil {
+				result.fieldAlgorithms = make(map[string]MaskingAlgorithm)
+			}
+			result.fieldAlgorithms[field] = algorithm
+		}
+		if fn, ok := base.fieldMaskFuncs[field]; ok {
+			if result.fieldMaskFuncs == nil {
+				result.fieldMaskFuncs = make(map[string]FieldMaskFunc)
+			}
+			result.fieldMaskFuncs[field] = fn
+		}
+	}
+	for name, g := range base.groups {
+		other, ok := src.groups[name]
+		if !ok {
+			continue
+		}
+		var fields []string
+		for _, field := range g.fields {
+			if other.normalized[normalizeFieldName(field)] {
+				fields = append(fields, field)
+			}
+		}
+		common := newFieldGroup(fields)
+		common.enabled = g.enabled && other.enabled
+		if result.groups == nil {
+			result.groups = make(map[string]*fieldGroup)
+		}
+		result.groups[name] = common
+	}
+	for internal, external := range base.aliases {
+		if src.aliases[internal] == external {
+			if result.aliases == nil {
+				result.aliases = make(map[string]string)
+			}
+			result.aliases[internal] = external
+		}
+	}
+	for _, re := range base.fieldPatterns {
+		for _, otherRe := range src.fieldPatterns {
+			if re.String() == otherRe.String() {
+				result.fieldPatterns = append(result.fieldPatterns, re)
+				break
+			}
+		}
+	}
+	for _, vp := range base.valuePatterns {
+		if src.hasValuePattern(vp) {
+			result.valuePatterns = append(result.valuePatterns, vp)
+		}
+	}
+
+	return result
+}
+
+// hasValuePattern 判断是否已存在相同的字段值匹配模式
+// 调用方需持有锁，或过滤器未被共享
+func (f *SensitiveDataFilter) hasValuePattern(vp *valuePattern) bool {
+	for _, existing := range f.valuePatterns {
+		if existing.re.String() == vp.re.String() {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,51 @@
+package zaploggerfilter
+
+import (
+	"fmt"
+	"sync/atomic"
+
+	"go.uber.org/zap"
+)
+
+// MissingLoggerPolicy 向不存在的目标日志记录器记录日志时的处理方式
+type MissingLoggerPolicy int
+
+const (
+	// DropEntry 丢弃日志条目（默认）
+	DropEntry MissingLoggerPolicy = iota
+	// UseGlobalLogger 改为向全局日志记录器记录
+	UseGlobalLogger
+	// PanicOnMissing 触发panic，用于将缺少日志记录器视为编程错误的严格环境
+	PanicOnMissing
+)
+
+// missingLoggerPolicy 当前使用的处理方式
+var missingLoggerPolicy atomic.Int32
+
+// SetMissingLoggerPolicy 设置向不存在的目标记录日志时的处理方式，
+// 应用于LogTo及DebugTo等按目标记录日志的函数、LogToCtx和EmitEntry
+func SetMissingLoggerPolicy(policy MissingLoggerPolicy) {
+	missingLoggerPolicy.Store(int32(policy))
+}
+
+// GetMissingLoggerPolicy 获取向不存在的目标记录日志时的处理方式
+func GetMissingLoggerPolicy() MissingLoggerPolicy {
+	return MissingLoggerPolicy(missingLoggerPolicy.Load())
+}
+
+// targetLogger 获取用于记录日志的目标日志记录器，目标不存在时按MissingLoggerPolicy处理
+// 返回: 日志记录器，丢弃条目时为nil；目标日志记录器是否存在
+func targetLogger(target string) (*zap.Logger, bool) {
+	if nl, ok := loadLogger(target); ok {
+		return nl.logger, true
+	}
+
+	switch GetMissingLoggerPolicy() {
+	case UseGlobalLogger:
+		return GetGlobalLogger(), false
+	case PanicOnMissing:
+		panic(fmt.Sprintf("zaploggerfilter: logger %q not found", target))
+	default:
+		return nil, false
+	}
+}
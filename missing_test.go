@@ -0,0 +1,109 @@
+package zaploggerfilter
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"go.uber.org/zap/zapcore"
+)
+
+// missingLoggerEmitters 按目标记录日志并返回目标是否存在的函数
+var missingLoggerEmitters = map[string]func(target, msg string) bool{
+	"LogTo": func(target, msg string) bool {
+		return LogTo(target, zapcore.InfoLevel, msg)
+	},
+	"LogToCtx": func(target, msg string) bool {
+		return LogToCtx(context.Background(), target, zapcore.InfoLevel, msg)
+	},
+	"EmitEntry": func(target, msg string) bool {
+		return EmitEntry(LogEntry{Target: target, Level: zapcore.InfoLevel, Msg: msg})
+	},
+}
+
+// setMissingLoggerPolicy 设置处理方式，测试结束时恢复默认值
+func setMissingLoggerPolicy(t *testing.T, policy MissingLoggerPolicy) {
+	t.Helper()
+
+	SetMissingLoggerPolicy(policy)
+	t.Cleanup(func() { SetMissingLoggerPolicy(DropEntry) })
+}
+
+func TestMissingLoggerPolicyDropEntry(t *testing.T) {
+	for name, emit := range missingLoggerEmitters {
+		t.Run(name, func(t *testing.T) {
+			var buf syncBuffer
+			initTestLoggers(t, bufferConfig("app", &buf))
+			setMissingLoggerPolicy(t, DropEntry)
+
+			if emit("missing", "dropped") {
+				t.Error("missing target reported as found")
+			}
+			if got := buf.String(); got != "" {
+				t.Errorf("output = %q, want empty", got)
+			}
+		})
+	}
+}
+
+func TestMissingLoggerPolicyUseGlobalLogger(t *testing.T) {
+	for name, emit := range missingLoggerEmitters {
+		t.Run(name, func(t *testing.T) {
+			var buf syncBuffer
+			initTestLoggers(t, bufferConfig("app", &buf))
+			setMissingLoggerPolicy(t, UseGlobalLogger)
+
+			if emit("missing", "fallback") {
+				t.Error("missing target reported as found")
+			}
+			if got := buf.String(); !strings.Contains(got, "fallback") {
+				t.Errorf("output = %q, want entry written to global logger", got)
+			}
+		})
+	}
+}
+
+func TestMissingLoggerPolicyPanicOnMissing(t *testing.T) {
+	for name, emit := range missingLoggerEmitters {
+		t.Run(name, func(t *testing.T) {
+			var buf syncBuffer
+			initTestLoggers(t, bufferConfig("app", &buf))
+			setMissingLoggerPolicy(t, PanicOnMissing)
+
+			defer func() {
+				r := recover()
+				if r == nil {
+					t.Fatal("expected panic for missing target")
+				}
+				if msg, _ := r.(string); !strings.Contains(msg, `"missing"`) {
+					t.Errorf("panic = %v, want target name", r)
+				}
+				if got := buf.String(); got != "" {
+					t.Errorf("output = %q, want empty", got)
+				}
+			}()
+			emit("missing", "never written")
+		})
+	}
+}
+
+func TestMissingLoggerPolicyExistingTarget(t *testing.T) {
+	policies := []MissingLoggerPolicy{DropEntry, UseGlobalLogger, PanicOnMissing}
+	for name, emit := range missingLoggerEmitters {
+		t.Run(name, func(t *testing.T) {
+			for _, policy := range policies {
+				var buf syncBuffer
+				initTestLoggers(t, bufferConfig("app", &buf))
+				SetMissingLoggerPolicy(policy)
+
+				if !emit("app", "written") {
+					t.Errorf("policy %d: existing target reported as missing", policy)
+				}
+				if got := buf.String(); strings.Count(got, "written") != 1 {
+					t.Errorf("policy %d: output = %q, want one entry", policy, got)
+				}
+			}
+			SetMissingLoggerPolicy(DropEntry)
+		})
+	}
+}
@@ -0,0 +1,56 @@
+package zaploggerfilter
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"go.uber.org/zap"
+)
+
+// namespaceSeparator 命名空间路径分隔符
+const namespaceSeparator = "."
+
+// RegisterNamespace 在父日志记录器下注册子命名空间
+// 子日志记录器由父日志记录器通过zap.Namespace(child)派生，存储在 parent+"."+child 下，
+// 之后可以通过 GetTargetLogger("db.queries") 等方式获取
+// 带命名空间前缀的敏感字段（如 "queries.sql"）对子日志记录器记录的字段同样生效
+func RegisterNamespace(parent, child string) error {
+	if child == "" || strings.Contains(child, namespaceSeparator) {
+		return fmt.Errorf("invalid namespace: %q", child)
+	}
+
+	nl, ok := loadLogger(parent)
+	if !ok {
+		return fmt.Errorf("%w: %s", ErrLoggerNotFound, parent)
+	}
+
+	l.Store(parent+namespaceSeparator+child, &namedLogger{
+		logger:    nl.logger.With(zap.Namespace(child)),
+		filter:    nl.filter,
+		syncers:   nl.syncers,
+		batch:     nl.batch,
+		counters:  nl.counters,
+		level:     nl.level,
+		derived:   true,
+		namespace: joinNamespace(nl.namespace, child),
+	})
+	return nil
+}
+
+// ListNamespaceChildren 列出父日志记录器下直接注册的子命名空间，按名称排序
+func ListNamespaceChildren(parent string) []string {
+	prefix := parent + namespaceSeparator
+
+	var children []string
+	l.Range(func(k, _ interface{}) bool {
+		name, ok := strings.CutPrefix(k.(string), prefix)
+		if ok && name != "" && !strings.Contains(name, namespaceSeparator) {
+			children = append(children, name)
+		}
+		return true
+	})
+
+	sort.Strings(children)
+	return children
+}
@@ -0,0 +1,50 @@
+package zaploggerfilter
+
+import (
+	"strings"
+	"testing"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+func TestRegisterNamespaceQualifiedFields(t *testing.T) {
+	var buf syncBuffer
+	initTestLoggers(t, bufferConfig("app", &buf, "payment.card"))
+	if err := RegisterNamespace("app", "payment"); err != nil {
+		t.Fatalf("RegisterNamespace() error = %v", err)
+	}
+
+	InfoTo("app", "inline", zap.Namespace("payment"), zap.String("card", "4111"))
+	InfoTo("app.payment", "child", zap.String("card", "4111"))
+
+	out := buf.String()
+	if strings.Contains(out, "4111") {
+		t.Errorf("qualified field not masked: %s", out)
+	}
+	if got := strings.Count(out, `"card": "***"`); got != 2 {
+		t.Errorf("masked card count = %d, want 2: %s", got, out)
+	}
+}
+
+func TestWithFieldsFilterNamespace(t *testing.T) {
+	var buf syncBuffer
+	initTestLoggers(t, bufferConfig("app", &buf, "password"))
+	if err := RegisterNamespace("app", "payment"); err != nil {
+		t.Fatalf("RegisterNamespace() error = %v", err)
+	}
+	filter := NewSensitiveDataFilter([]string{"payment.cvv"})
+	if err := WithFieldsFilter("app.payment", "app.payment.checkout", filter); err != nil {
+		t.Fatalf("WithFieldsFilter() error = %v", err)
+	}
+
+	lg, _ := GetTargetLogger("app")
+	core := &filteringCore{Core: lg.Core(), filter: filter}
+	zap.New(core).With(zap.Namespace("payment")).Info("with", zap.String("cvv", "123"))
+	LogTo("app.payment.checkout", zapcore.InfoLevel, "derived", zap.String("cvv", "456"))
+
+	out := buf.String()
+	if strings.Contains(out, "123") || strings.Contains(out, "456") {
+		t.Errorf("qualified field not masked: %s", out)
+	}
+}
@@ -0,0 +1,148 @@
+// Package natssink 提供向NATS（包括JetStream）发布日志的日志核心
+// 导入该包后即可在zaploggerfilter的配置中使用NATS类型：
+//
+//	import _ "github.com/november4bin/zap-logger-filter/natssink"
+package natssink
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/nats-io/nats.go"
+	zaploggerfilter "github.com/november4bin/zap-logger-filter"
+	"go.uber.org/zap/zapcore"
+)
+
+func init() {
+	zaploggerfilter.RegisterEncoderCoreBuilder(zaploggerfilter.NATS, func(cfg zaploggerfilter.Config, enc zapcore.Encoder, level zapcore.LevelEnabler) (zapcore.Core, error) {
+		return newCore(enc, cfg.Servers, cfg.Subject, level, Options{
+			ReconnectBufSize: cfg.NATSReconnectBufSize,
+		})
+	})
+}
+
+// defaultNATSFlushTimeout Sync等待NATS服务器确认的超时时间
+const defaultNATSFlushTimeout = 5 * time.Second
+
+// Options NATS日志核心的选项
+type Options struct {
+	// ReconnectBufSize 断开连接期间最多缓存的日志字节数，超过后写入返回错误，默认为8MB
+	ReconnectBufSize int
+	// Options 额外的NATS连接选项，如认证和TLS配置
+	Options []nats.Option
+}
+
+// NewCore 创建向NATS发送日志的日志核心，每条JSON格式的日志作为一条消息发布到subject
+// 使用JetStream时，创建捕获subject的流即可持久化日志。连接断开后会持续重连，
+// 期间的日志缓存在客户端中，重连后发送；首次连接失败时同样在后台重试。Sync会等待服务器确认已发送的消息
+// 返回的日志核心实现io.Closer，不再使用时需调用Close关闭连接；通过配置创建的日志核心由Reset等关闭
+func NewCore(servers []string, subject string, level zapcore.Level) (zapcore.Core, error) {
+	return NewCoreWithOptions(servers, subject, level, Options{})
+}
+
+// NewCoreWithOptions 使用选项创建NATS日志核心，见NewCore
+func NewCoreWithOptions(servers []string, subject string, level zapcore.Level, opts Options) (zapcore.Core, error) {
+	return newCore(zapcore.NewJSONEncoder(zaploggerfilter.DefaultEncoderConfig()), servers, subject, level, opts)
+}
+
+// newCore 使用指定的编码器创建NATS日志核心
+func newCore(encoder zapcore.Encoder, servers []string, subject string, level zapcore.LevelEnabler, opts Options) (zapcore.Core, error) {
+	if len(servers) == 0 {
+		return nil, errors.New("missing nats servers")
+	}
+	if subject == "" {
+		return nil, errors.New("missing nats subject")
+	}
+
+	natsOpts := []nats.Option{
+		nats.MaxReconnects(-1),
+		nats.RetryOnFailedConnect(true),
+		nats.DisconnectErrHandler(func(_ *nats.Conn, err error) {
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "zaploggerfilter: nats disconnected: %v\n", err)
+			}
+		}),
+	}
+	if opts.ReconnectBufSize > 0 {
+		natsOpts = append(natsOpts, nats.ReconnectBufSize(opts.ReconnectBufSize))
+	}
+	natsOpts = append(natsOpts, opts.Options...)
+
+	conn, err := nats.Connect(strings.Join(servers, ","), natsOpts...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to nats: %w", err)
+	}
+
+	return &natsCore{
+		LevelEnabler: level,
+		enc:          encoder,
+		conn:         conn,
+		subject:      subject,
+	}, nil
+}
+
+// natsCore 发布到NATS的日志核心，通过With派生的核心共享同一个连接
+type natsCore struct {
+	zapcore.LevelEnabler
+	enc     zapcore.Encoder
+	conn    *nats.Conn
+	subject string
+}
+
+// With 实现zapcore.Core接口
+func (c *natsCore) With(fields []zapcore.Field) zapcore.Core {
+	enc := c.enc.Clone()
+	for _, field := range fields {
+		field.AddTo(enc)
+	}
+	return &natsCore{LevelEnabler: c.LevelEnabler, enc: enc, conn: c.conn, subject: c.subject}
+}
+
+// Check 实现zapcore.Core接口
+func (c *natsCore) Check(ent zapcore.Entry, ce *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	if c.Enabled(ent.Level) {
+		return ce.AddCore(ent, c)
+	}
+	return ce
+}
+
+// Write 实现zapcore.Core接口，断开连接期间缓存已满时返回错误
+func (c *natsCore) Write(ent zapcore.Entry, fields []zapcore.Field) error {
+	buf, err := c.enc.EncodeEntry(ent, fields)
+	if err != nil {
+		return err
+	}
+	// Publish会复制消息内容，返回后即可释放缓冲区
+	err = c.conn.Publish(c.subject, bytes.TrimSpace(buf.Bytes()))
+	buf.Free()
+	if err != nil {
+		return fmt.Errorf("failed to publish nats message: %w", err)
+	}
+	return nil
+}
+
+// Sync 实现zapcore.Core接口，等待服务器确认已发送的消息
+// 正在重连时缓存的消息会在重连后发送，不视为错误
+func (c *natsCore) Sync() error {
+	if c.conn.IsReconnecting() {
+		return nil
+	}
+	if err := c.conn.FlushTimeout(defaultNATSFlushTimeout); err != nil {
+		return fmt.Errorf("failed to flush nats messages: %w", err)
+	}
+	return nil
+}
+
+// Close 实现io.Closer接口，发送缓存的消息后关闭连接，停止后台重连
+// 通过With派生的核心共享同一个连接，关闭后均不可再写入
+func (c *natsCore) Close() error {
+	if !c.conn.IsReconnecting() {
+		_ = c.conn.FlushTimeout(defaultNATSFlushTimeout)
+	}
+	c.conn.Close()
+	return nil
+}
@@ -0,0 +1,29 @@
+package natssink
+
+import (
+	"strings"
+	"testing"
+
+	zaploggerfilter "github.com/november4bin/zap-logger-filter"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+func TestCoreWithFiltersFields(t *testing.T) {
+	core := &natsCore{
+		LevelEnabler: zapcore.DebugLevel,
+		enc: &zaploggerfilter.SensitiveDataEncoder{
+			Encoder: zapcore.NewJSONEncoder(zapcore.EncoderConfig{MessageKey: "msg"}),
+			Filter:  zaploggerfilter.NewSensitiveDataFilter([]string{"password"}),
+		},
+	}
+
+	derived := core.With([]zapcore.Field{zap.String("password", "hunter2")}).(*natsCore)
+	out, err := derived.enc.EncodeEntry(zapcore.Entry{Message: "login"}, nil)
+	if err != nil {
+		t.Fatalf("EncodeEntry() error = %v", err)
+	}
+	if strings.Contains(out.String(), "hunter2") {
+		t.Errorf("With field not masked: %s", out.String())
+	}
+}
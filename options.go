@@ -0,0 +1,183 @@
+package zaploggerfilter
+
+import (
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// FileOption NewFileCore的选项
+type FileOption func(cfg *Config)
+
+// ConsoleOption NewConsoleCore的选项
+type ConsoleOption func(cfg *Config)
+
+// WithMaxSize 设置单个日志文件的最大尺寸（MB）
+func WithMaxSize(maxSize int) FileOption {
+	return func(cfg *Config) { cfg.MaxSize = maxSize }
+}
+
+// WithMaxAge 设置日志文件的最大保留天数
+func WithMaxAge(maxAge int) FileOption {
+	return func(cfg *Config) { cfg.MaxAge = maxAge }
+}
+
+// WithMaxBackups 设置最多保留的日志文件数
+func WithMaxBackups(maxBackups int) FileOption {
+	return func(cfg *Config) { cfg.MaxBackups = maxBackups }
+}
+
+// WithCompression 压缩轮转后的旧日志文件
+func WithCompression() FileOption {
+	return func(cfg *Config) { cfg.Compress = true }
+}
+
+// WithPaths 同时写入的其他日志文件路径
+func WithPaths(paths ...string) FileOption {
+	return func(cfg *Config) { cfg.Paths = append(cfg.Paths, paths...) }
+}
+
+// WithPrettyJSON 以缩进格式输出JSON，仅用于开发环境
+func WithPrettyJSON() FileOption {
+	return func(cfg *Config) { cfg.PrettyJSON = true }
+}
+
+// WithFileTimeFormat 设置文件日志的时间格式，见Config.TimeFormat
+func WithFileTimeFormat(format string) FileOption {
+	return func(cfg *Config) { cfg.TimeFormat = format }
+}
+
+// WithColorOutput 按日志级别输出彩色日志
+func WithColorOutput() ConsoleOption {
+	return func(cfg *Config) { cfg.ColorOutput = true }
+}
+
+// WithConsoleTimeFormat 设置控制台日志的时间格式，见Config.TimeFormat
+func WithConsoleTimeFormat(format string) ConsoleOption {
+	return func(cfg *Config) { cfg.TimeFormat = format }
+}
+
+// NewFileCore 创建写入文件的日志记录器核心，无需填写Config
+// filter不为nil时使用敏感数据过滤编码器
+func NewFileCore(name, path, level string, filter *SensitiveDataFilter, opts ...FileOption) (zapcore.Core, error) {
+	cfg := Config{Type: File, Name: name, Path: path, Level: level}
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+	return newCore(cfg, filter)
+}
+
+// NewConsoleCore 创建输出到标准输出的日志记录器核心，无需填写Config
+// filter不为nil时使用敏感数据过滤编码器
+func NewConsoleCore(name, level string, filter *SensitiveDataFilter, opts ...ConsoleOption) (zapcore.Core, error) {
+	cfg := Config{Type: Console, Name: name, Level: level}
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+	return newCore(cfg, filter)
+}
+
+// InitOption InitWithOptions的选项
+type InitOption interface {
+	apply(s *initSettings)
+}
+
+// initSettings 应用InitOption后的初始化设置
+type initSettings struct {
+	configs       []Config
+	options       InitOptions
+	defaultLevel  *zapcore.Level
+	globalFields  []zapcore.Field
+	encoderConfig *zapcore.EncoderConfig
+}
+
+// initOptionFunc 以函数实现的InitOption
+type initOptionFunc func(s *initSettings)
+
+func (f initOptionFunc) apply(s *initSettings) { f(s) }
+
+// apply 实现InitOption接口，设置GlobalCores
+func (o InitOptions) apply(s *initSettings) {
+	s.options.GlobalCores = append(s.options.GlobalCores, o.GlobalCores...)
+}
+
+// WithConfigs 添加日志记录器配置，可多次使用
+func WithConfigs(cfg []Config) InitOption {
+	return initOptionFunc(func(s *initSettings) { s.configs = append(s.configs, cfg...) })
+}
+
+// WithDefaultLevel 设置本次初始化创建的默认日志记录器的日志级别，代替DefaultLogLevel
+func WithDefaultLevel(level zapcore.Level) InitOption {
+	return initOptionFunc(func(s *initSettings) { s.defaultLevel = &level })
+}
+
+// WithGlobalFields 为全局日志记录器L添加字段，与初始化后调用With的效果一致
+func WithGlobalFields(fields ...zapcore.Field) InitOption {
+	return initOptionFunc(func(s *initSettings) { s.globalFields = append(s.globalFields, fields...) })
+}
+
+// WithEncoderConfig 替换本次初始化中内置输出类型使用的编码器配置，通过AddTargetLogger等在之后创建的日志记录器也会使用该配置
+// 再次初始化或Reset后不再生效；配置了TimeFormat的日志记录器仍使用TimeFormat指定的时间格式
+func WithEncoderConfig(ec zapcore.EncoderConfig) InitOption {
+	return initOptionFunc(func(s *initSettings) { s.encoderConfig = &ec })
+}
+
+// InitWithOptions 使用初始化选项初始化日志记录器，仅第一次成功的调用会执行初始化
+// 已经初始化时返回ErrAlreadyInitialized；配置无效时返回错误，且不会修改已有的日志记录器
+func InitWithOptions(opts ...InitOption) error {
+	done, err := initOnce(opts...)
+	if err != nil {
+		return err
+	}
+	if !done {
+		return ErrAlreadyInitialized
+	}
+	return nil
+}
+
+// newInitSettings 应用初始化选项
+func newInitSettings(opts []InitOption) initSettings {
+	var s initSettings
+	for _, opt := range opts {
+		opt.apply(&s)
+	}
+	return s
+}
+
+// initOnce 未初始化时按初始化选项初始化日志记录器，返回本次调用是否执行了初始化
+func initOnce(opts ...InitOption) (bool, error) {
+	s := newInitSettings(opts)
+
+	initMu.Lock()
+	defer initMu.Unlock()
+
+	if initialized {
+		return false, nil
+	}
+	if err := applyInit(s); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// reinit 按初始化选项重新初始化日志记录器
+func reinit(opts ...InitOption) error {
+	s := newInitSettings(opts)
+
+	initMu.Lock()
+	defer initMu.Unlock()
+
+	return applyInit(s)
+}
+
+// applyInit 按初始化设置创建并存储日志记录器，调用方需持有initMu
+func applyInit(s initSettings) error {
+	if err := initLoggers(s); err != nil {
+		return err
+	}
+	initEncoderConfig.Store(s.encoderConfig)
+	if len(s.globalFields) > 0 {
+		replaceGlobalLogger(func(lg *zap.Logger) *zap.Logger { return lg.With(s.globalFields...) })
+	}
+	initialized = true
+	return nil
+}
@@ -0,0 +1,42 @@
+package zaploggerfilter
+
+import (
+	"bytes"
+	"encoding/json"
+
+	"go.uber.org/zap/buffer"
+	"go.uber.org/zap/zapcore"
+)
+
+// prettyPool 格式化输出使用的缓冲池
+var prettyPool = buffer.NewPool()
+
+// prettyJSONEncoder 以两个空格缩进格式化输出JSON的编码器，仅适用于开发环境
+type prettyJSONEncoder struct {
+	zapcore.Encoder
+	// lineEnding 编码器配置的行尾
+	lineEnding string
+}
+
+// Clone 实现zapcore.Encoder接口
+func (e *prettyJSONEncoder) Clone() zapcore.Encoder {
+	return &prettyJSONEncoder{Encoder: e.Encoder.Clone(), lineEnding: e.lineEnding}
+}
+
+// EncodeEntry 实现zapcore.Encoder接口
+func (e *prettyJSONEncoder) EncodeEntry(ent zapcore.Entry, fields []zapcore.Field) (*buffer.Buffer, error) {
+	buf, err := e.Encoder.EncodeEntry(ent, fields)
+	if err != nil {
+		return nil, err
+	}
+	defer buf.Free()
+
+	var indented bytes.Buffer
+	if err = json.Indent(&indented, bytes.TrimSpace(buf.Bytes()), "", "  "); err != nil {
+		return nil, err
+	}
+	pretty := prettyPool.Get()
+	_, _ = pretty.Write(indented.Bytes())
+	pretty.AppendString(e.lineEnding)
+	return pretty, nil
+}
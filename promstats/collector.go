@@ -0,0 +1,66 @@
+// Package promstats 将命名日志记录器的统计信息导出为Prometheus指标
+//
+//	prometheus.MustRegister(promstats.NewCollector())
+package promstats
+
+import (
+	"sort"
+
+	zaploggerfilter "github.com/november4bin/zap-logger-filter"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+var (
+	// entriesDesc 各级别写入的日志条目数
+	entriesDesc = prometheus.NewDesc(
+		"zaploggerfilter_entries_total",
+		"Number of log entries written by the named logger.",
+		[]string{"logger", "level"}, nil,
+	)
+	// writeErrorsDesc 写入失败的次数
+	writeErrorsDesc = prometheus.NewDesc(
+		"zaploggerfilter_write_errors_total",
+		"Number of failed writes of the named logger.",
+		[]string{"logger"}, nil,
+	)
+	// lastLogDesc 最后一次写入日志的时间
+	lastLogDesc = prometheus.NewDesc(
+		"zaploggerfilter_last_log_timestamp_seconds",
+		"Unix time of the last log entry written by the named logger.",
+		[]string{"logger"}, nil,
+	)
+)
+
+// collector 在每次采集时读取zaploggerfilter.AllLoggerStats的Prometheus收集器
+type collector struct{}
+
+// NewCollector 创建导出所有命名日志记录器统计信息的Prometheus收集器
+// 指标按日志记录器名称区分，派生的日志记录器计入父日志记录器；从未写入的日志记录器不导出最后写入时间
+func NewCollector() prometheus.Collector {
+	return collector{}
+}
+
+// Describe 实现prometheus.Collector接口
+func (collector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- entriesDesc
+	ch <- writeErrorsDesc
+	ch <- lastLogDesc
+}
+
+// Collect 实现prometheus.Collector接口
+func (collector) Collect(ch chan<- prometheus.Metric) {
+	for name, stats := range zaploggerfilter.AllLoggerStats() {
+		levels := make([]string, 0, len(stats.EntriesByLevel))
+		for level := range stats.EntriesByLevel {
+			levels = append(levels, level)
+		}
+		sort.Strings(levels)
+		for _, level := range levels {
+			ch <- prometheus.MustNewConstMetric(entriesDesc, prometheus.CounterValue, float64(stats.EntriesByLevel[level]), name, level)
+		}
+		ch <- prometheus.MustNewConstMetric(writeErrorsDesc, prometheus.CounterValue, float64(stats.WriteErrors), name)
+		if !stats.LastLogTime.IsZero() {
+			ch <- prometheus.MustNewConstMetric(lastLogDesc, prometheus.GaugeValue, float64(stats.LastLogTime.UnixNano())/1e9, name)
+		}
+	}
+}
@@ -0,0 +1,73 @@
+package promstats
+
+import (
+	"strings"
+	"testing"
+
+	zaploggerfilter "github.com/november4bin/zap-logger-filter"
+	"github.com/prometheus/client_golang/prometheus"
+	"go.uber.org/zap/zapcore"
+)
+
+func TestCollector(t *testing.T) {
+	err := zaploggerfilter.Reinit([]zaploggerfilter.Config{{
+		Type:        zaploggerfilter.Console,
+		Name:        "app",
+		Level:       "info",
+		WriteSyncer: zapcore.AddSync(new(strings.Builder)),
+	}})
+	if err != nil {
+		t.Fatalf("Reinit() error = %v", err)
+	}
+	t.Cleanup(func() { _ = zaploggerfilter.Reset() })
+
+	if err := zaploggerfilter.WithFields("app", "app.child"); err != nil {
+		t.Fatalf("WithFields() error = %v", err)
+	}
+	zaploggerfilter.InfoTo("app", "first")
+	zaploggerfilter.InfoTo("app.child", "second")
+	zaploggerfilter.WarnTo("app", "third")
+
+	reg := prometheus.NewPedanticRegistry()
+	reg.MustRegister(NewCollector())
+	families, err := reg.Gather()
+	if err != nil {
+		t.Fatalf("Gather() error = %v", err)
+	}
+
+	// 键为指标名称和标签值
+	got := make(map[string]float64)
+	for _, family := range families {
+		for _, m := range family.GetMetric() {
+			key := family.GetName()
+			for _, label := range m.GetLabel() {
+				key += "," + label.GetValue()
+			}
+			got[key] = m.GetCounter().GetValue() + m.GetGauge().GetValue()
+		}
+	}
+
+	// 派生的日志记录器计入父日志记录器，不单独导出
+	want := map[string]float64{
+		"zaploggerfilter_entries_total,info,app":     2,
+		"zaploggerfilter_entries_total,warn,app":     1,
+		"zaploggerfilter_write_errors_total,app":     0,
+		"zaploggerfilter_write_errors_total,default": 0,
+	}
+	for key, value := range want {
+		if v, ok := got[key]; !ok || v != value {
+			t.Errorf("metric %s = %v (present %v), want %v", key, v, ok, value)
+		}
+	}
+	for key := range got {
+		if strings.Contains(key, "app.child") {
+			t.Errorf("derived logger exported separately: %s", key)
+		}
+	}
+	if _, ok := got["zaploggerfilter_last_log_timestamp_seconds,app"]; !ok {
+		t.Error("last log timestamp of app not exported")
+	}
+	if _, ok := got["zaploggerfilter_last_log_timestamp_seconds,default"]; ok {
+		t.Error("last log timestamp exported for a logger that never wrote")
+	}
+}
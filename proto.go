@@ -0,0 +1,57 @@
+package zaploggerfilter
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+)
+
+// protoMarshalOptions protobuf消息的JSON序列化选项
+// 使用proto中定义的字段名（默认为snake_case），便于与敏感字段列表匹配
+var protoMarshalOptions = protojson.MarshalOptions{
+	UseProtoNames: true,
+}
+
+// MaskProtoMessage 对protobuf消息中的敏感数据进行掩码处理
+// msg: 要处理的protobuf消息
+// filter: 敏感数据过滤器（如果为nil则不进行掩码）
+// 返回: 处理后的map，敏感字段值被替换为掩码
+func MaskProtoMessage(msg proto.Message, filter *SensitiveDataFilter) (map[string]interface{}, error) {
+	// 使用protojson序列化，字段名取自proto定义
+	jsonData, err := protoMarshalOptions.Marshal(msg)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal proto message: %w", err)
+	}
+
+	// 开启保留数值类型时按json.Number解析，由过滤器还原为整数或浮点数
+	dec := json.NewDecoder(bytes.NewReader(jsonData))
+	if filter != nil && filter.PreserveTypes() {
+		dec.UseNumber()
+	}
+	var dataMap map[string]interface{}
+	if err = dec.Decode(&dataMap); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal proto json: %w", err)
+	}
+
+	// 处理nil过滤器
+	if filter == nil {
+		return dataMap, nil
+	}
+
+	return filter.MaskSensitiveData(dataMap), nil
+}
+
+// ProtoField 创建一个对protobuf消息进行敏感数据过滤的zap字段
+// 如果消息无法序列化，返回记录错误信息的字段
+func ProtoField(key string, msg proto.Message, filter *SensitiveDataFilter) zapcore.Field {
+	maskedData, err := MaskProtoMessage(msg, filter)
+	if err != nil {
+		return zap.NamedError(key, err)
+	}
+	return zap.Any(key, maskedData)
+}
@@ -0,0 +1,174 @@
+package zaploggerfilter
+
+import (
+	"encoding"
+	"encoding/json"
+	"reflect"
+	"strings"
+)
+
+// maxReflectDepth 反射转换的最大嵌套深度，避免循环引用导致无限递归
+const maxReflectDepth = 32
+
+var (
+	jsonMarshalerType = reflect.TypeOf((*json.Marshaler)(nil)).Elem()
+	textMarshalerType = reflect.TypeOf((*encoding.TextMarshaler)(nil)).Elem()
+)
+
+// reflectValue 通过反射将结构体、键为字符串的map和切片转换为map[string]interface{}或[]interface{}
+// 标量字段保留原始的Go类型（如int64不会变为float64），字段名遵循json标签
+// 实现了json.Marshaler或encoding.TextMarshaler的类型不做转换
+// 返回: 转换后的值，以及是否进行了转换
+func reflectValue(value interface{}) (interface{}, bool) {
+	if value == nil {
+		return nil, false
+	}
+	return convertValue(reflect.ValueOf(value), 0)
+}
+
+// convertValue 转换反射值，见reflectValue
+func convertValue(rv reflect.Value, depth int) (interface{}, bool) {
+	if depth > maxReflectDepth {
+		return nil, false
+	}
+	for rv.Kind() == reflect.Pointer || rv.Kind() == reflect.Interface {
+		if rv.IsNil() {
+			return nil, false
+		}
+		if rv.Type().Implements(jsonMarshalerType) || rv.Type().Implements(textMarshalerType) {
+			return nil, false
+		}
+		rv = rv.Elem()
+	}
+	if rv.Type().Implements(jsonMarshalerType) || rv.Type().Implements(textMarshalerType) {
+		return nil, false
+	}
+
+	switch rv.Kind() {
+	case reflect.Struct:
+		result := make(map[string]interface{}, rv.NumField())
+		convertStruct(rv, result, depth)
+		return result, true
+	case reflect.Map:
+		if rv.Type().Key().Kind() != reflect.String || rv.IsNil() {
+			return nil, false
+		}
+		result := make(map[string]interface{}, rv.Len())
+		iter := rv.MapRange()
+		for iter.Next() {
+			result[iter.Key().String()] = elemValue(iter.Value(), depth+1)
+		}
+		return result, true
+	case reflect.Slice, reflect.Array:
+		// []byte按原值处理
+		if rv.Type().Elem().Kind() == reflect.Uint8 || (rv.Kind() == reflect.Slice && rv.IsNil()) {
+			return nil, false
+		}
+		result := make([]interface{}, rv.Len())
+		for i := range result {
+			result[i] = elemValue(rv.Index(i), depth+1)
+		}
+		return result, true
+	default:
+		return nil, false
+	}
+}
+
+// convertStruct 将结构体的导出字段写入result，匿名嵌入的结构体字段会被展开
+func convertStruct(rv reflect.Value, result map[string]interface{}, depth int) {
+	rt := rv.Type()
+	for i := 0; i < rt.NumField(); i++ {
+		sf := rt.Field(i)
+		name, omitEmpty, skip := jsonFieldName(sf)
+		if skip {
+			continue
+		}
+
+		fv := rv.Field(i)
+		if sf.Anonymous && name == "" {
+			// 展开匿名嵌入的结构体
+			for fv.Kind() == reflect.Pointer {
+				if fv.IsNil() {
+					break
+				}
+				fv = fv.Elem()
+			}
+			if fv.Kind() == reflect.Struct {
+				convertStruct(fv, result, depth)
+				continue
+			}
+			if !sf.IsExported() {
+				continue
+			}
+		}
+		if name == "" {
+			name = sf.Name
+		}
+		if omitEmpty && fv.IsZero() {
+			continue
+		}
+		result[name] = elemValue(fv, depth+1)
+	}
+}
+
+// jsonFieldName 解析结构体字段的json标签
+// 返回: 标签中的字段名（未设置时为空）、是否设置了omitempty、是否跳过该字段
+func jsonFieldName(sf reflect.StructField) (name string, omitEmpty, skip bool) {
+	if !sf.IsExported() && !sf.Anonymous {
+		return "", false, true
+	}
+	tag := sf.Tag.Get("json")
+	if tag == "-" {
+		return "", false, true
+	}
+	name, opts, _ := strings.Cut(tag, ",")
+	for _, opt := range strings.Split(opts, ",") {
+		if opt == "omitempty" {
+			omitEmpty = true
+		}
+	}
+	return name, omitEmpty, false
+}
+
+// elemValue 转换字段或元素的值，无需转换的值保留原始类型
+func elemValue(rv reflect.Value, depth int) interface{} {
+	if converted, ok := convertValue(rv, depth); ok {
+		return converted
+	}
+	if !rv.CanInterface() {
+		return nil
+	}
+	return rv.Interface()
+}
+
+// isZeroValue 判断值是否为nil、数值0、false或空字符串
+func isZeroValue(value interface{}) bool {
+	switch v := value.(type) {
+	case nil:
+		return true
+	case string:
+		return v == ""
+	case bool:
+		return !v
+	case json.Number:
+		n, err := v.Float64()
+		return err == nil && n == 0
+	}
+
+	rv := reflect.ValueOf(value)
+	switch rv.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr,
+		reflect.Float32, reflect.Float64:
+		return rv.IsZero()
+	case reflect.Pointer, reflect.Interface, reflect.Map, reflect.Slice:
+		return rv.IsNil()
+	}
+	return false
+}
+
+// isNilPointer 判断值是否为类型化的nil指针
+func isNilPointer(value interface{}) bool {
+	rv := reflect.ValueOf(value)
+	return rv.Kind() == reflect.Pointer && rv.IsNil()
+}
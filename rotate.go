@@ -0,0 +1,499 @@
+package zaploggerfilter
+
+import (
+	"compress/gzip"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"gopkg.in/natefinch/lumberjack.v2"
+)
+
+// rotatableWriter 可以手动轮转的文件输出
+type rotatableWriter interface {
+	io.WriteCloser
+	// Rotate 关闭当前文件，将其按备份文件名重命名后创建新文件
+	Rotate() error
+}
+
+// fileSyncer 文件日志输出，lumberjack写入时不缓冲，Sync不需要做任何处理
+type fileSyncer struct {
+	rotatableWriter
+}
+
+// Sync 实现zapcore.WriteSyncer接口
+func (*fileSyncer) Sync() error {
+	return nil
+}
+
+// RotateLogger 手动轮转目标日志记录器的所有日志文件，如在部署前转移日志文件
+// 目标不存在或不输出到日志文件（如Console类型或使用WriteSyncer的配置）时返回错误
+func RotateLogger(name string) error {
+	nl, ok := loadLogger(name)
+	if !ok {
+		return fmt.Errorf("%w: %s", ErrLoggerNotFound, name)
+	}
+
+	var errs []error
+	rotated := false
+	for _, s := range nl.syncers {
+		file, ok := s.(*fileSyncer)
+		if !ok {
+			continue
+		}
+		rotated = true
+		if err := file.Rotate(); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	if !rotated {
+		return fmt.Errorf("logger %q does not write to rotatable log files", name)
+	}
+	return errors.Join(errs...)
+}
+
+// newFileWriter 创建路径对应的文件输出
+// 设置了FilenameTemplate时，文件名由模板按当前时间生成并放在path所在目录中，path的文件名部分不使用，
+// 生成的文件名变化时关闭旧文件并切换到新文件
+func newFileWriter(cfg Config, path string) rotatableWriter {
+	newLumberjack := func(filename string) rotatableWriter {
+		lj := &lumberjack.Logger{
+			Filename:   filename,
+			MaxSize:    cfg.MaxSize,
+			MaxBackups: cfg.MaxBackups,
+			MaxAge:     cfg.MaxAge,
+			Compress:   cfg.Compress,
+			LocalTime:  cfg.LocalTime,
+		}
+		if cfg.MaxSizeBytes <= 0 {
+			return lj
+		}
+		// 按字节数轮转，lumberjack自身的轮转阈值设置为大于MaxSizeBytes，避免提前轮转
+		lj.MaxSize = int(cfg.MaxSizeBytes/megabyte) + 1
+		return newSizeWriter(lj, cfg.MaxSizeBytes)
+	}
+
+	if cfg.FilenameTemplate == "" {
+		return newLumberjack(path)
+	}
+	maxSize := cfg.MaxSizeBytes
+	if maxSize <= 0 {
+		maxSize = int64(cfg.MaxSize) * megabyte
+	}
+	if maxSize <= 0 {
+		maxSize = defaultMaxSize * megabyte
+	}
+	return &templateWriter{
+		dir:        filepath.Dir(path),
+		template:   cfg.FilenameTemplate,
+		localTime:  cfg.LocalTime,
+		maxSize:    maxSize,
+		maxBackups: cfg.MaxBackups,
+		maxAge:     time.Duration(cfg.MaxAge) * 24 * time.Hour,
+		compress:   cfg.Compress,
+	}
+}
+
+const (
+	// defaultMaxSize 未设置MaxSize时的轮转大小（MB），与lumberjack一致
+	defaultMaxSize = 100
+	// backupTimeFormat 轮转文件名中的时间格式，与lumberjack一致
+	backupTimeFormat = "2006-01-02T15-04-05.000"
+	// compressSuffix 压缩文件的后缀
+	compressSuffix = ".gz"
+)
+
+// templateWriter 按文件名模板切换文件的输出，每个文件按大小轮转，轮转文件的命名与lumberjack一致
+// 不使用lumberjack：其清理旧文件的goroutine在Close后不会退出，且只清理同一文件名的轮转文件。
+// 切换或轮转文件后，在后台按MaxBackups和MaxAge清理模板生成的所有旧文件（包括轮转文件），并按需压缩
+type templateWriter struct {
+	dir        string
+	template   string
+	localTime  bool
+	maxSize    int64
+	maxBackups int
+	maxAge     time.Duration
+	compress   bool
+
+	mu       sync.Mutex
+	filename string
+	file     *os.File
+	size     int64
+	// millMu 保证同时只有一个清理旧文件的goroutine
+	millMu sync.Mutex
+	// milling 正在运行的清理旧文件的goroutine，Close时等待其结束
+	milling sync.WaitGroup
+}
+
+// now 返回当前时间，未设置LocalTime时使用UTC
+func (w *templateWriter) now() time.Time {
+	if w.localTime {
+		return time.Now()
+	}
+	return time.Now().UTC()
+}
+
+// Write 实现io.Writer接口，当前文件写入后超过轮转大小时先轮转再写入
+func (w *templateWriter) Write(p []byte) (int, error) {
+	filename := filepath.Join(w.dir, formatFilename(w.template, w.now()))
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.file == nil || filename != w.filename {
+		if err := w.closeFile(); err != nil {
+			return 0, err
+		}
+		if err := w.openFile(filename); err != nil {
+			return 0, err
+		}
+		w.mill()
+	}
+	if w.size > 0 && w.size+int64(len(p)) > w.maxSize {
+		if err := w.rotate(); err != nil {
+			return 0, err
+		}
+	}
+	n, err := w.file.Write(p)
+	w.size += int64(n)
+	return n, err
+}
+
+// Close 关闭当前文件并等待后台清理结束，之后的写入会重新打开文件
+func (w *templateWriter) Close() error {
+	w.mu.Lock()
+	err := w.closeFile()
+	w.mu.Unlock()
+
+	w.milling.Wait()
+	return err
+}
+
+// Rotate 轮转当前文件，尚未写入任何内容时不做任何处理
+func (w *templateWriter) Rotate() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.file == nil {
+		return nil
+	}
+	return w.rotate()
+}
+
+// openFile 以追加方式打开文件，已有内容计入当前大小，调用方需持有mu
+func (w *templateWriter) openFile(filename string) error {
+	if err := os.MkdirAll(filepath.Dir(filename), 0o755); err != nil {
+		return fmt.Errorf("failed to create log directory: %w", err)
+	}
+	file, err := os.OpenFile(filename, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+	if err != nil {
+		return fmt.Errorf("failed to open log file: %w", err)
+	}
+	info, err := file.Stat()
+	if err != nil {
+		_ = file.Close()
+		return fmt.Errorf("failed to stat log file: %w", err)
+	}
+	w.filename, w.file, w.size = filename, file, info.Size()
+	return nil
+}
+
+// closeFile 关闭当前文件，调用方需持有mu
+func (w *templateWriter) closeFile() error {
+	if w.file == nil {
+		return nil
+	}
+	err := w.file.Close()
+	w.file = nil
+	return err
+}
+
+// rotate 关闭当前文件，按备份文件名重命名后创建新文件，调用方需持有mu
+func (w *templateWriter) rotate() error {
+	if err := w.closeFile(); err != nil {
+		return err
+	}
+	ext := filepath.Ext(w.filename)
+	backup := fmt.Sprintf("%s-%s%s", strings.TrimSuffix(w.filename, ext), w.now().Format(backupTimeFormat), ext)
+	if err := os.Rename(w.filename, backup); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("failed to rename log file: %w", err)
+	}
+	if err := w.openFile(w.filename); err != nil {
+		return err
+	}
+	w.mill()
+	return nil
+}
+
+// mill 在后台清理和压缩旧文件，调用方需持有mu
+func (w *templateWriter) mill() {
+	if w.maxBackups <= 0 && w.maxAge <= 0 && !w.compress {
+		return
+	}
+	w.milling.Add(1)
+	go func() {
+		defer w.milling.Done()
+		w.millMu.Lock()
+		defer w.millMu.Unlock()
+
+		if err := w.millOnce(); err != nil {
+			fmt.Fprintf(os.Stderr, "zaploggerfilter: failed to clean up log files: %v\n", err)
+		}
+	}()
+}
+
+// millOnce 删除超过MaxBackups或MaxAge的旧文件，并压缩剩余的旧文件
+// 旧文件为模板生成的文件及其轮转文件中除当前文件以外的文件，按修改时间从新到旧保留
+func (w *templateWriter) millOnce() error {
+	pattern := filepath.Join(w.dir, templateGlob(w.template))
+	matches, err := filepath.Glob(pattern)
+	if err != nil {
+		return err
+	}
+	compressed, err := filepath.Glob(pattern + compressSuffix)
+	if err != nil {
+		return err
+	}
+	generated := templateRegexp(w.template)
+	// 在列出文件之后获取当前文件，之后切换到的新文件不在列出的文件中
+	w.mu.Lock()
+	current := w.filename
+	w.mu.Unlock()
+
+	type oldFile struct {
+		path    string
+		modTime time.Time
+	}
+	var files []oldFile
+	for _, path := range append(matches, compressed...) {
+		rel, err := filepath.Rel(w.dir, path)
+		if path == current || err != nil || !generated.MatchString(filepath.ToSlash(rel)) {
+			continue
+		}
+		info, err := os.Stat(path)
+		if err != nil || !info.Mode().IsRegular() {
+			continue
+		}
+		files = append(files, oldFile{path: path, modTime: info.ModTime()})
+	}
+	sort.Slice(files, func(i, j int) bool { return files[i].modTime.After(files[j].modTime) })
+
+	var errs []error
+	cutoff := time.Now().Add(-w.maxAge)
+	for i, f := range files {
+		if (w.maxBackups > 0 && i >= w.maxBackups) || (w.maxAge > 0 && f.modTime.Before(cutoff)) {
+			if err := os.Remove(f.path); err != nil && !errors.Is(err, os.ErrNotExist) {
+				errs = append(errs, err)
+			}
+			continue
+		}
+		if w.compress && !strings.HasSuffix(f.path, compressSuffix) {
+			errs = append(errs, compressFile(f.path))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// templateGlob 将文件名模板转换为匹配其生成的文件及轮转文件的glob模式
+func templateGlob(template string) string {
+	var b strings.Builder
+	for i := 0; i < len(template); i++ {
+		c := template[i]
+		switch {
+		case c == '%' && i < len(template)-1 && template[i+1] == '%':
+			b.WriteByte('%')
+			i++
+		case c == '%' && i < len(template)-1:
+			b.WriteByte('*')
+			i++
+		case c == '*' || c == '?' || c == '[' || c == '\\':
+			b.WriteByte('\\')
+			b.WriteByte(c)
+		default:
+			b.WriteByte(c)
+		}
+	}
+	// 轮转文件在扩展名前添加时间
+	ext := filepath.Ext(b.String())
+	return strings.TrimSuffix(b.String(), ext) + "*" + ext
+}
+
+// templateRegexp 返回严格匹配模板生成的文件及其轮转文件、压缩文件的正则表达式，用于排除目录中的其他文件
+// 匹配的是相对于日志目录并使用/分隔的路径
+func templateRegexp(template string) *regexp.Regexp {
+	ext := filepath.Ext(template)
+	base := template[:len(template)-len(ext)]
+	return regexp.MustCompile("^" + templateRegexpPart(base) + `(-\d{4}-\d{2}-\d{2}T\d{2}-\d{2}-\d{2}\.\d{3})?` +
+		templateRegexpPart(ext) + `(\.gz)?$`)
+}
+
+// templateRegexpPart 将模板的一部分转换为正则表达式，格式占位符匹配对应位数的数字
+func templateRegexpPart(template string) string {
+	var b strings.Builder
+	for i := 0; i < len(template); i++ {
+		c := template[i]
+		if c != '%' || i == len(template)-1 {
+			b.WriteString(regexp.QuoteMeta(string(c)))
+			continue
+		}
+
+		i++
+		switch template[i] {
+		case 'Y':
+			b.WriteString(`\d{4}`)
+		case 'y', 'm', 'd', 'H', 'M', 'S':
+			b.WriteString(`\d{2}`)
+		case 'j':
+			b.WriteString(`\d{3}`)
+		case '%':
+			b.WriteString("%")
+		default:
+			b.WriteString(regexp.QuoteMeta(template[i-1 : i+1]))
+		}
+	}
+	return b.String()
+}
+
+// compressFile 将文件压缩为.gz文件后删除原文件
+func compressFile(path string) (err error) {
+	src, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer src.Close()
+
+	dst, err := os.OpenFile(path+compressSuffix, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0o644)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err != nil {
+			_ = os.Remove(path + compressSuffix)
+		}
+	}()
+
+	gz := gzip.NewWriter(dst)
+	if _, err = io.Copy(gz, src); err != nil {
+		_ = dst.Close()
+		return err
+	}
+	if err = gz.Close(); err != nil {
+		_ = dst.Close()
+		return err
+	}
+	if err = dst.Close(); err != nil {
+		return err
+	}
+	return os.Remove(path)
+}
+
+// megabyte lumberjack中MaxSize的单位
+const megabyte = 1024 * 1024
+
+// sizeWriter 按字节数轮转的输出，用于小于1MB的轮转粒度
+type sizeWriter struct {
+	lj      *lumberjack.Logger
+	maxSize int64
+
+	mu   sync.Mutex
+	size int64
+}
+
+// newSizeWriter 创建按字节数轮转的输出，已有文件的大小计入当前大小
+func newSizeWriter(lj *lumberjack.Logger, maxSize int64) *sizeWriter {
+	w := &sizeWriter{lj: lj, maxSize: maxSize}
+	if info, err := os.Stat(lj.Filename); err == nil {
+		w.size = info.Size()
+	}
+	return w
+}
+
+// Write 实现io.Writer接口，写入后超过maxSize时先轮转再写入
+func (w *sizeWriter) Write(p []byte) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.size > 0 && w.size+int64(len(p)) > w.maxSize {
+		if err := w.lj.Rotate(); err != nil {
+			return 0, err
+		}
+		w.size = 0
+	}
+	n, err := w.lj.Write(p)
+	w.size += int64(n)
+	return n, err
+}
+
+// Close 实现io.Closer接口
+func (w *sizeWriter) Close() error {
+	return w.lj.Close()
+}
+
+// Rotate 轮转当前文件并重置已写入的大小
+func (w *sizeWriter) Rotate() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if err := w.lj.Rotate(); err != nil {
+		return err
+	}
+	w.size = 0
+	return nil
+}
+
+// formatFilename 按strftime风格的模板生成文件名
+// 支持 %Y（四位年份）、%y（两位年份）、%m、%d、%H、%M、%S、%j（一年中的第几天）和 %%
+func formatFilename(template string, t time.Time) string {
+	var b strings.Builder
+	for i := 0; i < len(template); i++ {
+		c := template[i]
+		if c != '%' || i == len(template)-1 {
+			b.WriteByte(c)
+			continue
+		}
+
+		i++
+		switch template[i] {
+		case 'Y':
+			b.WriteString(strconv.Itoa(t.Year()))
+		case 'y':
+			b.WriteString(pad(t.Year()%100, 2))
+		case 'm':
+			b.WriteString(pad(int(t.Month()), 2))
+		case 'd':
+			b.WriteString(pad(t.Day(), 2))
+		case 'H':
+			b.WriteString(pad(t.Hour(), 2))
+		case 'M':
+			b.WriteString(pad(t.Minute(), 2))
+		case 'S':
+			b.WriteString(pad(t.Second(), 2))
+		case 'j':
+			b.WriteString(pad(t.YearDay(), 3))
+		case '%':
+			b.WriteByte('%')
+		default:
+			// 不支持的格式原样保留
+			b.WriteByte('%')
+			b.WriteByte(template[i])
+		}
+	}
+	return b.String()
+}
+
+// pad 将数字格式化为指定宽度，不足时补零
+func pad(n, width int) string {
+	s := strconv.Itoa(n)
+	if len(s) < width {
+		s = strings.Repeat("0", width-len(s)) + s
+	}
+	return s
+}
@@ -0,0 +1,106 @@
+package zaploggerfilter
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"testing"
+	"time"
+)
+
+// millNow 关闭文件并等待后台清理完成后，再清理一次旧文件
+func millNow(t *testing.T, w *templateWriter) {
+	t.Helper()
+
+	if err := w.Close(); err != nil {
+		t.Fatalf("Close() error = %v", err)
+	}
+	if err := w.millOnce(); err != nil {
+		t.Fatalf("millOnce() error = %v", err)
+	}
+}
+
+// listDir 列出目录中的文件名
+func listDir(t *testing.T, dir string) []string {
+	t.Helper()
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatalf("ReadDir() error = %v", err)
+	}
+	names := make([]string, 0, len(entries))
+	for _, e := range entries {
+		names = append(names, e.Name())
+	}
+	sort.Strings(names)
+	return names
+}
+
+func TestTemplateWriterPrunesOldFiles(t *testing.T) {
+	dir := t.TempDir()
+	// 模板生成的旧文件及其轮转文件，以及目录中的其他文件
+	old := map[string]time.Duration{
+		"app-2020-01-01.log":                         72 * time.Hour,
+		"app-2020-01-02-2020-01-02T10-00-00.000.log": 48 * time.Hour,
+		"app-2020-01-02.log":                         24 * time.Hour,
+		"other.log":                                  96 * time.Hour,
+		"app-notes.log":                              96 * time.Hour,
+	}
+	for name, age := range old {
+		path := filepath.Join(dir, name)
+		if err := os.WriteFile(path, []byte("old\n"), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		mtime := time.Now().Add(-age)
+		if err := os.Chtimes(path, mtime, mtime); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	cfg := Config{Path: filepath.Join(dir, "ignored.log"), FilenameTemplate: "app-%Y-%m-%d.log", MaxBackups: 1}
+	w := newFileWriter(cfg, cfg.Path).(*templateWriter)
+	if _, err := w.Write([]byte("new\n")); err != nil {
+		t.Fatalf("Write() error = %v", err)
+	}
+	millNow(t, w)
+
+	current := filepath.Base(w.filename)
+	want := []string{"app-2020-01-02.log", "app-notes.log", current, "other.log"}
+	sort.Strings(want)
+	if got := listDir(t, dir); !slices.Equal(got, want) {
+		t.Errorf("files after cleanup = %v, want %v", got, want)
+	}
+}
+
+func TestTemplateWriterRotateCompresses(t *testing.T) {
+	dir := t.TempDir()
+	cfg := Config{Path: filepath.Join(dir, "app.log"), FilenameTemplate: "app-%Y.log", MaxSizeBytes: 8, Compress: true}
+	w := newFileWriter(cfg, cfg.Path).(*templateWriter)
+
+	// 第二次写入超过轮转大小，先轮转再写入
+	for _, line := range []string{"first\n", "second\n"} {
+		if _, err := w.Write([]byte(line)); err != nil {
+			t.Fatalf("Write() error = %v", err)
+		}
+	}
+	millNow(t, w)
+
+	names := listDir(t, dir)
+	if len(names) != 2 {
+		t.Fatalf("files after rotation = %v, want the current file and one compressed backup", names)
+	}
+	var compressed int
+	for _, name := range names {
+		if filepath.Ext(name) == compressSuffix {
+			compressed++
+		}
+	}
+	if compressed != 1 {
+		t.Errorf("files after rotation = %v, want one compressed backup", names)
+	}
+	data, err := os.ReadFile(w.filename)
+	if err != nil || string(data) != "second\n" {
+		t.Errorf("current file = %q (%v), want the second line", data, err)
+	}
+}
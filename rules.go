@@ -0,0 +1,197 @@
+package zaploggerfilter
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// MaskingRules 掩码规则，可从独立的规则文件（JSON或YAML）加载
+//
+// 规则文件示例（YAML）：
+//
+//	fields:
+//	  - password
+//	  - token
+//	field_patterns:
+//	  - ".*_secret$"
+//	value_patterns:
+//	  - pattern: "Bearer [A-Za-z0-9._-]+"
+//	    replacement: "Bearer ***"
+//	field_masks:
+//	  card_number: "[CARD]"
+type MaskingRules struct {
+	// Fields 需要被视为敏感的字段名称列表
+	Fields []string `json:"fields" yaml:"fields"`
+	// FieldPatterns 字段名匹配的正则表达式，大小写不敏感
+	FieldPatterns []string `json:"field_patterns" yaml:"field_patterns"`
+	// ValuePatterns 字段值匹配的正则表达式，匹配的内容会被替换
+	ValuePatterns []ValuePatternRule `json:"value_patterns" yaml:"value_patterns"`
+	// FieldMasks 字段级别的掩码字符串，配置的字段同时会被视为敏感字段
+	FieldMasks map[string]string `json:"field_masks" yaml:"field_masks"`
+}
+
+// ValuePatternRule 字段值匹配规则
+type ValuePatternRule struct {
+	// Pattern 正则表达式
+	Pattern string `json:"pattern" yaml:"pattern"`
+	// Replacement 替换内容，为空时使用全局Mask
+	Replacement string `json:"replacement" yaml:"replacement"`
+}
+
+// LoadMaskingRules 从文件加载掩码规则
+// 根据文件扩展名选择格式：.yaml/.yml 为YAML，其他为JSON
+func LoadMaskingRules(path string) (*MaskingRules, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read masking rules: %w", err)
+	}
+
+	rules := &MaskingRules{}
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".yaml", ".yml":
+		err = yaml.Unmarshal(data, rules)
+	default:
+		err = json.Unmarshal(data, rules)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse masking rules: %w", err)
+	}
+
+	// 提前编译正则表达式，尽早发现无效规则
+	if _, _, err = rules.compile(); err != nil {
+		return nil, err
+	}
+
+	return rules, nil
+}
+
+// compile 编译规则中的正则表达式
+func (r *MaskingRules) compile() ([]*regexp.Regexp, []*valuePattern, error) {
+	fieldPatterns := make([]*regexp.Regexp, 0, len(r.FieldPatterns))
+	for _, pattern := range r.FieldPatterns {
+		re, err := regexp.Compile("(?i)" + pattern)
+		if err != nil {
+			return nil, nil, fmt.Errorf("invalid field pattern %q: %w", pattern, err)
+		}
+		fieldPatterns = append(fieldPatterns, re)
+	}
+
+	valuePatterns := make([]*valuePattern, 0, len(r.ValuePatterns))
+	for _, rule := range r.ValuePatterns {
+		re, err := regexp.Compile(rule.Pattern)
+		if err != nil {
+			return nil, nil, fmt.Errorf("invalid value pattern %q: %w", rule.Pattern, err)
+		}
+		replacement := rule.Replacement
+		if replacement == "" {
+			replacement = Mask
+		}
+		valuePatterns = append(valuePatterns, &valuePattern{re: re, replacement: replacement})
+	}
+
+	return fieldPatterns, valuePatterns, nil
+}
+
+// ApplyMaskingRules 将掩码规则合并到已有的过滤器中
+// 规则只会追加或更新，不会移除过滤器中已有的规则
+func ApplyMaskingRules(filter *SensitiveDataFilter, rules *MaskingRules) error {
+	if filter == nil || rules == nil {
+		return nil
+	}
+
+	fieldPatterns, valuePatterns, err := rules.compile()
+	if err != nil {
+		return err
+	}
+
+	filter.mu.Lock()
+	defer filter.mu.Unlock()
+
+	for _, field := range rules.Fields {
+		filter.sensitiveFields[normalizeFieldName(field)] = true
+	}
+	for _, re := range fieldPatterns {
+		filter.addFieldPattern(re)
+	}
+	for _, vp := range valuePatterns {
+		filter.addValuePattern(vp)
+	}
+	if len(rules.FieldMasks) > 0 && filter.fieldMasks == nil {
+		filter.fieldMasks = make(map[string]string, len(rules.FieldMasks))
+	}
+	for field, mask := range rules.FieldMasks {
+		lowerField := normalizeFieldName(field)
+		filter.sensitiveFields[lowerField] = true
+		filter.fieldMasks[lowerField] = mask
+	}
+
+	return nil
+}
+
+// WatchMaskingRules 加载规则文件并合并到过滤器中，随后按interval定期检查文件变化并重新加载
+// 重新加载失败时错误会输出到标准错误，过滤器保持原有规则
+// 返回: 停止监听的函数
+func WatchMaskingRules(path string, filter *SensitiveDataFilter, interval time.Duration) (stop func(), err error) {
+	if interval <= 0 {
+		return nil, fmt.Errorf("invalid watch interval: %s", interval)
+	}
+
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to stat masking rules: %w", err)
+	}
+	rules, err := LoadMaskingRules(path)
+	if err != nil {
+		return nil, err
+	}
+	if err = ApplyMaskingRules(filter, rules); err != nil {
+		return nil, err
+	}
+
+	done := make(chan struct{})
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		modTime, size := info.ModTime(), info.Size()
+		for {
+			select {
+			case <-done:
+				return
+			case <-ticker.C:
+			}
+
+			current, statErr := os.Stat(path)
+			if statErr != nil {
+				fmt.Fprintf(os.Stderr, "zaploggerfilter: failed to stat masking rules: %v\n", statErr)
+				continue
+			}
+			// 文件未发生变化
+			if current.ModTime().Equal(modTime) && current.Size() == size {
+				continue
+			}
+			modTime, size = current.ModTime(), current.Size()
+
+			reloaded, loadErr := LoadMaskingRules(path)
+			if loadErr == nil {
+				loadErr = ApplyMaskingRules(filter, reloaded)
+			}
+			if loadErr != nil {
+				fmt.Fprintf(os.Stderr, "zaploggerfilter: failed to reload masking rules: %v\n", loadErr)
+			}
+		}
+	}()
+
+	var once sync.Once
+	return func() {
+		once.Do(func() { close(done) })
+	}, nil
+}
@@ -1,21 +1,103 @@
 package zaploggerfilter
 
 import (
+	"bytes"
+	"encoding"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"log/slog"
+	"os"
+	"regexp"
+	"slices"
 	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+	"unicode/utf8"
 
 	"go.uber.org/zap"
 	"go.uber.org/zap/buffer"
 	"go.uber.org/zap/zapcore"
+	"golang.org/x/text/cases"
+	"golang.org/x/text/unicode/norm"
 )
 
 // Mask 掩码字符串
+//
+// Deprecated: 修改全局变量会与并发使用的过滤器产生数据竞争，使用SensitiveDataFilter.SetMaskFunc
 var Mask = "***"
 
+// MaskFunc 敏感字段的掩码函数，接收字段名和原始值，返回掩码后的值
+type MaskFunc func(fieldName, value string) string
+
+// FieldMaskFunc 字段级别的掩码函数，接收字段的原始值（字符串、数字或通过反射序列化的值等），返回掩码后的值
+// 经JSON序列化处理的值（如json.Marshaler的结果）中数字为json.Number；函数在不持有过滤器锁的情况下调用
+type FieldMaskFunc func(value interface{}) interface{}
+
+// DefaultMaskFunc 默认的掩码函数，返回全局Mask（默认为 "***"）
+func DefaultMaskFunc(fieldName, value string) string {
+	return Mask
+}
+
 // SensitiveDataFilter 负责敏感数据的检测和过滤
 type SensitiveDataFilter struct {
+	// mu 保护以下规则，允许在运行时（如热加载规则文件）修改
+	mu              sync.RWMutex
 	sensitiveFields map[string]bool
+	// fieldPatterns 字段名匹配模式
+	fieldPatterns []*regexp.Regexp
+	// valuePatterns 字段值匹配模式，匹配的内容会被替换
+	valuePatterns []*valuePattern
+	// fieldMasks 字段级别的掩码字符串
+	fieldMasks map[string]string
+	// fieldAlgorithms 字段级别的掩码算法，仅对字符串值生效
+	fieldAlgorithms map[string]MaskingAlgorithm
+	// fieldMaskFuncs 字段级别的掩码函数，对任意类型的值生效
+	fieldMaskFuncs map[string]FieldMaskFunc
+	// groups 敏感字段分组
+	groups map[string]*fieldGroup
+	// aliases 字段别名，键为内部字段名，值为敏感字段列表中使用的外部字段名，均已规范化
+	aliases map[string]string
+	// maskAlgorithm 过滤器默认的掩码算法，仅对字符串值生效
+	maskAlgorithm MaskingAlgorithm
+	// maskFunc 过滤器的掩码函数，在没有其他掩码配置时使用
+	maskFunc MaskFunc
+	// preserveTypes 是否将JSON数字还原为整数或浮点数类型
+	preserveTypes bool
+	// omitZeroValues 是否省略零值的非敏感字段
+	omitZeroValues bool
+	// messageMasking 是否对日志消息应用字段值匹配模式
+	messageMasking bool
+	// auditTrail 是否在日志条目中追加被掩码的字段名列表
+	auditTrail bool
+	// auditTrailKey 被掩码的字段名列表的键名，为空时使用DefaultAuditTrailKey
+	auditTrailKey string
+	// messageMatcher 合并所有字段值匹配模式的正则表达式，用于快速判断日志消息是否需要处理
+	// 字段值匹配模式变化时置为nil，使用时重新生成
+	messageMatcher atomic.Pointer[regexp.Regexp]
+	// debugLogger 调试模式的日志记录器，为nil时不输出调试信息
+	debugLogger atomic.Pointer[zap.Logger]
+	// disabled 是否停用过滤器，停用时不视任何字段为敏感字段，也不应用字段值匹配模式
+	disabled atomic.Bool
+	// stats 各字段的掩码次数，键为规范化后的字段名，值为*atomic.Int64，副本不继承
+	stats sync.Map
+}
+
+// valuePattern 字段值匹配模式
+// 设置了algorithm时使用掩码算法处理匹配内容，否则替换为replacement
+type valuePattern struct {
+	re          *regexp.Regexp
+	replacement string
+	algorithm   MaskingAlgorithm
+}
+
+// NewEnvironmentAwareFilter 创建按运行环境启用的敏感数据过滤器
+// prod为true（生产环境）时启用过滤器，否则停用，便于在开发环境中输出原始值进行调试
+func NewEnvironmentAwareFilter(fields []string, prod bool) *SensitiveDataFilter {
+	f := NewSensitiveDataFilter(fields)
+	f.SetEnabled(prod)
+	return f
 }
 
 // NewSensitiveDataFilter 创建一个新的敏感数据过滤器
@@ -24,61 +106,593 @@ func NewSensitiveDataFilter(fields []string) *SensitiveDataFilter {
 
 	sensitiveMap := make(map[string]bool, len(fields)+5) // 额外空间给信用卡相关字段
 
-	// 将所有字段转换为小写并存储
+	// 将所有字段规范化后存储
 	for _, field := range fields {
-		lowerField := strings.ToLower(field)
-		sensitiveMap[lowerField] = true
+		sensitiveMap[normalizeFieldName(field)] = true
 	}
 
 	return &SensitiveDataFilter{
 		sensitiveFields: sensitiveMap,
+		maskFunc:        DefaultMaskFunc,
+	}
+}
+
+// NewSensitiveDataFilterWithPatterns 创建同时按字段名匹配模式检测敏感字段的过滤器
+// patterns: 字段名的正则表达式，如 `_token$`、`secret`、`^card_number_\d+$`，与掩码规则文件中的字段名模式相同，
+// 不区分大小写，未使用^和$锚定时匹配字段名的任意部分。IsSensitiveField先检查exactFields，再依次检查模式
+// 返回: 模式无法编译时返回错误
+func NewSensitiveDataFilterWithPatterns(exactFields []string, patterns []string) (*SensitiveDataFilter, error) {
+	fieldPatterns, _, err := (&MaskingRules{FieldPatterns: patterns}).compile()
+	if err != nil {
+		return nil, err
+	}
+
+	f := NewSensitiveDataFilter(exactFields)
+	for _, re := range fieldPatterns {
+		f.addFieldPattern(re)
+	}
+	return f, nil
+}
+
+// Clone 创建过滤器的深拷贝，对副本的修改不会影响原过滤器
+func (f *SensitiveDataFilter) Clone() *SensitiveDataFilter {
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+
+	clone := &SensitiveDataFilter{
+		sensitiveFields: make(map[string]bool, len(f.sensitiveFields)),
+		fieldPatterns:   append([]*regexp.Regexp(nil), f.fieldPatterns...),
+		valuePatterns:   append([]*valuePattern(nil), f.valuePatterns...),
+		maskAlgorithm:   f.maskAlgorithm,
+		maskFunc:        f.maskFunc,
+		preserveTypes:   f.preserveTypes,
+		omitZeroValues:  f.omitZeroValues,
+		messageMasking:  f.messageMasking,
+		auditTrail:      f.auditTrail,
+		auditTrailKey:   f.auditTrailKey,
+	}
+	clone.debugLogger.Store(f.debugLogger.Load())
+	clone.disabled.Store(f.disabled.Load())
+	for field, sensitive := range f.sensitiveFields {
+		clone.sensitiveFields[field] = sensitive
+	}
+	if f.fieldMasks != nil {
+		clone.fieldMasks = make(map[string]string, len(f.fieldMasks))
+		for field, mask := range f.fieldMasks {
+			clone.fieldMasks[field] = mask
+		}
+	}
+	if f.groups != nil {
+		clone.groups = make(map[string]*fieldGroup, len(f.groups))
+		for name, g := range f.groups {
+			clone.groups[name] = g.clone()
+		}
+	}
+	if f.aliases != nil {
+		clone.aliases = make(map[string]string, len(f.aliases))
+		for internal, external := range f.aliases {
+			clone.aliases[internal] = external
+		}
+	}
+	if f.fieldAlgorithms != nil {
+		clone.fieldAlgorithms = make(map[string]MaskingAlgorithm, len(f.fieldAlgorithms))
+		for field, algorithm := range f.fieldAlgorithms {
+			clone.fieldAlgorithms[field] = algorithm
+		}
+	}
+	if f.fieldMaskFuncs != nil {
+		clone.fieldMaskFuncs = make(map[string]FieldMaskFunc, len(f.fieldMaskFuncs))
+		for field, fn := range f.fieldMaskFuncs {
+			clone.fieldMaskFuncs[field] = fn
+		}
 	}
+	return clone
+}
+
+// AddField 添加敏感字段
+func (f *SensitiveDataFilter) AddField(fields ...string) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	for _, field := range fields {
+		f.sensitiveFields[normalizeFieldName(field)] = true
+	}
+}
+
+// RemoveField 移除敏感字段，同时移除字段级别的掩码配置
+func (f *SensitiveDataFilter) RemoveField(fields ...string) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	for _, field := range fields {
+		lowerField := normalizeFieldName(field)
+		delete(f.sensitiveFields, lowerField)
+		delete(f.fieldMasks, lowerField)
+		delete(f.fieldAlgorithms, lowerField)
+		delete(f.fieldMaskFuncs, lowerField)
+	}
+}
+
+// normalizeFieldName 规范化字段名，用于大小写不敏感的比较
+// 先去除首尾空白和包围的双引号（如HTTP头解析得到的 " Authorization" 或JSON键 `"token"`），
+// ASCII字段名直接转换为小写；其他字段名先进行NFC规范化，再进行与语言无关的Unicode大小写折叠，
+// 因此德语的ß与SS、ss视为相同；土耳其语的İ折叠为i加组合点（U+0307），与i、I不视为相同
+func normalizeFieldName(fieldName string) string {
+	fieldName = strings.TrimSpace(fieldName)
+	if len(fieldName) >= 2 && fieldName[0] == '"' && fieldName[len(fieldName)-1] == '"' {
+		fieldName = strings.TrimSpace(fieldName[1 : len(fieldName)-1])
+	}
+
+	for i := 0; i < len(fieldName); i++ {
+		if fieldName[i] >= utf8.RuneSelf {
+			// cases.Caser有状态，不能在goroutine间共享
+			return cases.Fold().String(norm.NFC.String(fieldName))
+		}
+	}
+	return strings.ToLower(fieldName)
 }
 
 // IsSensitiveField 检查给定字段名是否为敏感字段
 // fieldName: 要检查的字段名
 // 返回: 如果是敏感字段则返回true
 func (f *SensitiveDataFilter) IsSensitiveField(fieldName string) bool {
-	if fieldName == "" {
+	if fieldName == "" || !f.Enabled() {
 		return false
 	}
-	// 转换为小写以实现大小写不敏感的比较
-	lowerField := strings.ToLower(fieldName)
-	// 检查是否在敏感字段列表中
-	return f.sensitiveFields[lowerField]
+	// 规范化字段名以实现大小写不敏感的比较
+	lowerField := normalizeFieldName(fieldName)
+
+	matched, pattern := f.matchSensitiveField(lowerField)
+	if lg := f.debugLogger.Load(); lg != nil {
+		fields := []zapcore.Field{zap.String("field", fieldName), zap.Bool("matched", matched)}
+		if pattern != "" {
+			fields = append(fields, zap.String("pattern", pattern))
+		}
+		lg.Debug("check sensitive field", fields...)
+	}
+	return matched
+}
+
+// matchSensitiveField 检查规范化后的字段名是否为敏感字段，字段名或其别名对应的外部字段名匹配时均视为敏感字段
+// 返回: 是否为敏感字段，以及匹配的字段名模式（通过敏感字段列表匹配时为空）
+func (f *SensitiveDataFilter) matchSensitiveField(lowerField string) (bool, string) {
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+
+	if matched, pattern := f.matchFieldLocked(lowerField); matched {
+		return true, pattern
+	}
+	if external, ok := f.aliases[lowerField]; ok {
+		return f.matchFieldLocked(external)
+	}
+	return false, ""
+}
+
+// matchFieldLocked 检查规范化后的字段名是否在敏感字段列表中或匹配字段名模式，调用方需持有锁
+func (f *SensitiveDataFilter) matchFieldLocked(lowerField string) (bool, string) {
+	// 检查是否在敏感字段列表或已启用的分组中
+	if f.sensitiveFields[lowerField] || f.inEnabledGroup(lowerField) {
+		return true, ""
+	}
+	// 检查是否匹配字段名模式
+	for _, re := range f.fieldPatterns {
+		if re.MatchString(lowerField) {
+			return true, re.String()
+		}
+	}
+	return false, ""
+}
+
+// AddAlias 添加字段别名，使内部字段名按外部字段名判断是否为敏感字段
+// 例如 AddAlias("pwd", "password") 后，password为敏感字段时pwd也视为敏感字段，
+// 并使用password的字段级别配置。别名是单向的，不会使password按pwd判断
+func (f *SensitiveDataFilter) AddAlias(internalName, externalName string) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if f.aliases == nil {
+		f.aliases = make(map[string]string)
+	}
+	f.aliases[normalizeFieldName(internalName)] = normalizeFieldName(externalName)
+}
+
+// EnableDebugMasking 开启调试模式，将检查的字段名及是否匹配、匹配的字段名和字段值模式、
+// MaskSensitiveData遍历的嵌套深度以Debug级别输出到debugLogger，用于验证敏感字段配置
+// debugLogger为nil时关闭调试模式；关闭时仅有一次原子读取的开销，不应在生产环境中开启
+// debugLogger不应使用同一个过滤器，以免调试信息被递归处理
+func (f *SensitiveDataFilter) EnableDebugMasking(debugLogger *zap.Logger) {
+	f.debugLogger.Store(debugLogger)
+}
+
+// SetEnabled 在运行时启用或停用过滤器，不会改变敏感字段列表等规则
+// 停用时IsSensitiveField始终返回false，字段值匹配模式也不会生效，日志按原样输出
+func (f *SensitiveDataFilter) SetEnabled(enabled bool) {
+	f.disabled.Store(!enabled)
+}
+
+// Enabled 获取过滤器是否启用，默认启用
+func (f *SensitiveDataFilter) Enabled() bool {
+	return !f.disabled.Load()
+}
+
+// SetMaskAlgorithm 设置过滤器默认的掩码算法
+// 敏感字段的字符串值在没有字段级别配置时使用该算法处理，设置为nil时使用掩码函数
+func (f *SensitiveDataFilter) SetMaskAlgorithm(algorithm MaskingAlgorithm) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.maskAlgorithm = algorithm
+}
+
+// MaskAlgorithm 获取过滤器默认的掩码算法
+func (f *SensitiveDataFilter) MaskAlgorithm() MaskingAlgorithm {
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+
+	return f.maskAlgorithm
+}
+
+// SetMaskFunc 设置过滤器的掩码函数
+// 敏感字段在没有字段级别配置和掩码算法时使用该函数处理，非字符串值会先格式化为字符串，
+// 设置为nil时恢复为DefaultMaskFunc
+func (f *SensitiveDataFilter) SetMaskFunc(fn MaskFunc) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if fn == nil {
+		fn = DefaultMaskFunc
+	}
+	f.maskFunc = fn
+}
+
+// MaskFunc 获取过滤器的掩码函数
+func (f *SensitiveDataFilter) MaskFunc() MaskFunc {
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+
+	if f.maskFunc == nil {
+		return DefaultMaskFunc
+	}
+	return f.maskFunc
+}
+
+// SetPreserveTypes 设置是否保留数值类型
+// 开启后MaskSensitiveData会将json.Number（如使用json.Decoder.UseNumber解析得到的值）还原为
+// int64（整数）或float64，MaskProtoMessage也会保留整数类型，避免整数被转换为float64导致日志结构推断不一致
+func (f *SensitiveDataFilter) SetPreserveTypes(enabled bool) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.preserveTypes = enabled
+}
+
+// PreserveTypes 获取是否保留数值类型
+func (f *SensitiveDataFilter) PreserveTypes() bool {
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+
+	return f.preserveTypes
+}
+
+// SetOmitZeroValues 设置是否省略零值字段
+// 开启后MaskSensitiveData和通过zap.Any等记录的结构体会丢弃原始值为nil、0、false或空字符串的非敏感字段，
+// 敏感字段无论原始值如何都会保留掩码后的值
+func (f *SensitiveDataFilter) SetOmitZeroValues(enabled bool) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.omitZeroValues = enabled
+}
+
+// SetMessageMasking 设置是否对日志消息进行掩码处理
+// 开启后SensitiveDataEncoder会对日志消息应用字段值匹配模式，用于处理 fmt.Sprintf("token=%s", token)
+// 这类非结构化的日志消息。每条日志消息都需要进行匹配，因此默认关闭
+func (f *SensitiveDataFilter) SetMessageMasking(enabled bool) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.messageMasking = enabled
+}
+
+// MessageMasking 获取是否对日志消息进行掩码处理
+func (f *SensitiveDataFilter) MessageMasking() bool {
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+
+	return f.messageMasking
+}
+
+// maskMessage 对日志消息应用字段值匹配模式
+// 先使用合并所有模式的正则表达式判断是否有匹配，没有匹配时不再逐个应用模式
+func (f *SensitiveDataFilter) maskMessage(msg string) string {
+	if msg == "" {
+		return msg
+	}
+	matcher := f.messageMatcher.Load()
+	if matcher == nil {
+		if matcher = f.buildMessageMatcher(); matcher == nil {
+			return msg
+		}
+	}
+	if !matcher.MatchString(msg) {
+		return msg
+	}
+	masked, _ := f.maskString(msg)
+	return masked
+}
+
+// buildMessageMatcher 生成合并所有字段值匹配模式的正则表达式，没有字段值匹配模式时返回nil
+func (f *SensitiveDataFilter) buildMessageMatcher() *regexp.Regexp {
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+
+	if len(f.valuePatterns) == 0 {
+		return nil
+	}
+	alternatives := make([]string, 0, len(f.valuePatterns))
+	for _, vp := range f.valuePatterns {
+		alternatives = append(alternatives, "(?:"+vp.re.String()+")")
+	}
+	matcher := regexp.MustCompile(strings.Join(alternatives, "|"))
+	f.messageMatcher.Store(matcher)
+	return matcher
+}
+
+// OmitZeroValues 获取是否省略零值字段
+func (f *SensitiveDataFilter) OmitZeroValues() bool {
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+
+	return f.omitZeroValues
+}
+
+// numberValue 将json.Number还原为int64，无法表示为整数时还原为float64
+func numberValue(n json.Number) interface{} {
+	if i, err := n.Int64(); err == nil {
+		return i
+	}
+	if v, err := n.Float64(); err == nil {
+		return v
+	}
+	return n
+}
+
+// maskValue 获取敏感字段值的掩码结果
+// 优先级：字段配置的掩码函数、字段配置的掩码算法（仅字符串值）、字段配置的掩码字符串、
+// 过滤器默认的掩码算法（仅字符串值）、过滤器的掩码函数
+func (f *SensitiveDataFilter) maskValue(fieldName string, value interface{}) interface{} {
+	lowerField := normalizeFieldName(fieldName)
+	f.countMask(lowerField)
+
+	f.mu.RLock()
+	// 字段本身没有字段级别配置时使用别名对应的外部字段名的配置
+	if external, ok := f.aliases[lowerField]; ok && !f.hasFieldConfig(lowerField) {
+		lowerField = external
+	}
+	fieldFn, hasFieldFn := f.fieldMaskFuncs[lowerField]
+	fieldAlgorithm, hasFieldAlgorithm := f.fieldAlgorithms[lowerField]
+	mask, hasMask := f.fieldMasks[lowerField]
+	maskAlgorithm, maskFunc := f.maskAlgorithm, f.maskFunc
+	f.mu.RUnlock()
+
+	// 释放锁之后再调用用户的掩码函数和算法，避免其调用过滤器的设置方法时死锁
+	if hasFieldFn {
+		return fieldFn(value)
+	}
+	s, isString := value.(string)
+	if isString && hasFieldAlgorithm {
+		return fieldAlgorithm.Mask(s)
+	}
+	if hasMask {
+		return mask
+	}
+	if isString && maskAlgorithm != nil {
+		return maskAlgorithm.Mask(s)
+	}
+	if maskFunc == nil {
+		return Mask
+	}
+	if !isString {
+		s = fmt.Sprint(value)
+	}
+	return maskFunc(fieldName, s)
+}
+
+// hasFieldConfig 判断字段是否有字段级别的掩码字符串、掩码算法或掩码函数，调用方需持有锁
+func (f *SensitiveDataFilter) hasFieldConfig(lowerField string) bool {
+	if _, ok := f.fieldMasks[lowerField]; ok {
+		return true
+	}
+	if _, ok := f.fieldMaskFuncs[lowerField]; ok {
+		return true
+	}
+	_, ok := f.fieldAlgorithms[lowerField]
+	return ok
+}
+
+// SetFieldMaskFunc 设置字段的掩码函数，并将该字段标记为敏感字段
+// 掩码函数接收字段的原始值，返回值代替掩码字符串输出，优先于字段的其他掩码配置，
+// 例如邮箱保留域名、手机号保留后四位；fn为nil时移除字段的掩码函数
+func (f *SensitiveDataFilter) SetFieldMaskFunc(fieldName string, fn FieldMaskFunc) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	lowerField := normalizeFieldName(fieldName)
+	if fn == nil {
+		delete(f.fieldMaskFuncs, lowerField)
+		return
+	}
+	if f.fieldMaskFuncs == nil {
+		f.fieldMaskFuncs = make(map[string]FieldMaskFunc)
+	}
+	f.sensitiveFields[lowerField] = true
+	f.fieldMaskFuncs[lowerField] = fn
+}
+
+// hasFieldMaskFunc 判断字段或其别名对应的外部字段是否设置了掩码函数
+func (f *SensitiveDataFilter) hasFieldMaskFunc(fieldName string) bool {
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+
+	if len(f.fieldMaskFuncs) == 0 {
+		return false
+	}
+	lowerField := normalizeFieldName(fieldName)
+	if _, ok := f.fieldMaskFuncs[lowerField]; ok {
+		return true
+	}
+	if external, ok := f.aliases[lowerField]; ok && !f.hasFieldConfig(lowerField) {
+		_, ok = f.fieldMaskFuncs[external]
+		return ok
+	}
+	return false
+}
+
+// countMask 增加字段的掩码次数
+func (f *SensitiveDataFilter) countMask(lowerField string) {
+	counter, ok := f.stats.Load(lowerField)
+	if !ok {
+		counter, _ = f.stats.LoadOrStore(lowerField, new(atomic.Int64))
+	}
+	counter.(*atomic.Int64).Add(1)
+}
+
+// Stats 返回各字段掩码次数的副本，键为规范化后的字段名
+func (f *SensitiveDataFilter) Stats() map[string]int64 {
+	stats := make(map[string]int64)
+	f.stats.Range(func(k, v interface{}) bool {
+		stats[k.(string)] = v.(*atomic.Int64).Load()
+		return true
+	})
+	return stats
+}
+
+// ResetStats 将所有字段的掩码次数清零
+func (f *SensitiveDataFilter) ResetStats() {
+	f.stats.Range(func(k, v interface{}) bool {
+		v.(*atomic.Int64).Store(0)
+		return true
+	})
+}
+
+// setFieldAlgorithm 设置字段的掩码算法并将字段标记为敏感字段
+// 调用方需持有写锁
+func (f *SensitiveDataFilter) setFieldAlgorithm(fieldName string, algorithm MaskingAlgorithm) {
+	lowerField := normalizeFieldName(fieldName)
+	if f.fieldAlgorithms == nil {
+		f.fieldAlgorithms = make(map[string]MaskingAlgorithm)
+	}
+	f.sensitiveFields[lowerField] = true
+	f.fieldAlgorithms[lowerField] = algorithm
+}
+
+// maskString 使用字段值匹配模式对字符串进行掩码处理
+// 返回: 处理后的字符串，以及是否有内容被替换
+func (f *SensitiveDataFilter) maskString(value string) (string, bool) {
+	if value == "" || !f.Enabled() {
+		return value, false
+	}
+
+	lg := f.debugLogger.Load()
+	var matched []string
+
+	f.mu.RLock()
+	masked := value
+	for _, vp := range f.valuePatterns {
+		if lg != nil && vp.re.MatchString(masked) {
+			matched = append(matched, vp.re.String())
+		}
+		if vp.algorithm != nil {
+			masked = vp.re.ReplaceAllStringFunc(masked, vp.algorithm.Mask)
+		} else {
+			masked = vp.re.ReplaceAllString(masked, vp.replacement)
+		}
+	}
+	f.mu.RUnlock()
+
+	// 释放锁后再输出调试信息，避免调试日志记录器使用同一个过滤器时死锁
+	for _, pattern := range matched {
+		lg.Debug("value pattern matched", zap.String("pattern", pattern))
+	}
+	return masked, masked != value
+}
+
+// addFieldPattern 添加字段名匹配模式，已存在的相同模式会被忽略
+// 调用方需持有写锁
+func (f *SensitiveDataFilter) addFieldPattern(re *regexp.Regexp) {
+	for _, existing := range f.fieldPatterns {
+		if existing.String() == re.String() {
+			return
+		}
+	}
+	f.fieldPatterns = append(f.fieldPatterns, re)
+}
+
+// addValuePattern 添加字段值匹配模式，已存在的相同模式会更新其替换内容
+// 调用方需持有写锁
+func (f *SensitiveDataFilter) addValuePattern(vp *valuePattern) {
+	f.messageMatcher.Store(nil)
+	for i, existing := range f.valuePatterns {
+		if existing.re.String() == vp.re.String() {
+			f.valuePatterns[i] = vp
+			return
+		}
+	}
+	f.valuePatterns = append(f.valuePatterns, vp)
 }
 
 // MaskSensitiveData 递归地对map中的敏感数据进行掩码处理
 // data: 要处理的数据（如果为nil则返回nil）
 // 返回: 处理后的数据，敏感字段值被替换为掩码
 func (f *SensitiveDataFilter) MaskSensitiveData(data map[string]interface{}) map[string]interface{} {
+	return f.maskMapData(data, 0)
+}
+
+// maskMapData 处理map中的敏感数据
+// depth: 当前的嵌套深度，用于调试模式的输出
+func (f *SensitiveDataFilter) maskMapData(data map[string]interface{}, depth int) map[string]interface{} {
 	// 处理nil输入
 	if data == nil {
 		return nil
 	}
+	if lg := f.debugLogger.Load(); lg != nil {
+		lg.Debug("mask map", zap.Int("depth", depth), zap.Int("keys", len(data)))
+	}
 
 	result := make(map[string]interface{}, len(data))
+	omitZero := f.OmitZeroValues()
 
 	for key, value := range data {
 		// 检查键是否为敏感字段
-		lowerKey := strings.ToLower(key)
-		if f.IsSensitiveField(lowerKey) {
-			result[key] = Mask
+		if f.IsSensitiveField(key) {
+			result[key] = f.maskValue(key, value)
 			continue
 		}
 
 		// 递归处理嵌套结构
+		var masked interface{}
 		switch v := value.(type) {
 		case map[string]interface{}:
 			// 递归处理嵌套的map
-			result[key] = f.MaskSensitiveData(v)
+			masked = f.maskMapData(v, depth+1)
 		case []interface{}:
 			// 处理切片类型
-			result[key] = f.maskSliceData(v)
+			masked = f.maskSliceData(v, depth+1)
+		case string:
+			// 对字符串内容应用字段值匹配模式
+			masked, _ = f.maskString(v)
+		case json.Number:
+			masked = f.maskNumber(v)
 		default:
-			// 保留原始值，不检查内容
-			result[key] = v
+			// 结构体等类型通过反射展开后处理，标量保留原始值
+			masked = f.maskReflected(v, depth+1)
 		}
+
+		// 按掩码处理前的原始值判断是否为零值
+		if omitZero && isZeroValue(value) {
+			continue
+		}
+		result[key] = masked
 	}
 
 	return result
@@ -86,12 +700,16 @@ func (f *SensitiveDataFilter) MaskSensitiveData(data map[string]interface{}) map
 
 // maskSliceData 处理切片中的敏感数据
 // slice: 要处理的切片（如果为nil则返回nil）
+// depth: 当前的嵌套深度，用于调试模式的输出
 // 返回: 处理后的切片
-func (f *SensitiveDataFilter) maskSliceData(slice []interface{}) []interface{} {
+func (f *SensitiveDataFilter) maskSliceData(slice []interface{}, depth int) []interface{} {
 	// 处理nil输入
 	if slice == nil {
 		return nil
 	}
+	if lg := f.debugLogger.Load(); lg != nil {
+		lg.Debug("mask slice", zap.Int("depth", depth), zap.Int("items", len(slice)))
+	}
 
 	result := make([]interface{}, len(slice))
 
@@ -99,19 +717,56 @@ func (f *SensitiveDataFilter) maskSliceData(slice []interface{}) []interface{} {
 		switch v := item.(type) {
 		case map[string]interface{}:
 			// 递归处理嵌套的map
-			result[i] = f.MaskSensitiveData(v)
+			result[i] = f.maskMapData(v, depth+1)
 		case []interface{}:
 			// 递归处理嵌套的切片
-			result[i] = f.maskSliceData(v)
+			result[i] = f.maskSliceData(v, depth+1)
+		case string:
+			// 对字符串内容应用字段值匹配模式
+			result[i], _ = f.maskString(v)
+		case json.Number:
+			result[i] = f.maskNumber(v)
 		default:
-			// 保留原始值，不检查内容
-			result[i] = v
+			// 结构体等类型通过反射展开后处理，标量保留原始值
+			result[i] = f.maskReflected(v, depth+1)
 		}
 	}
 
 	return result
 }
 
+// maskNumber 处理json.Number，开启保留数值类型时还原为int64或float64
+func (f *SensitiveDataFilter) maskNumber(n json.Number) interface{} {
+	if f.PreserveTypes() {
+		return numberValue(n)
+	}
+	return n
+}
+
+// maskReflected 通过反射展开结构体、map和切片并进行掩码处理
+// 实现了json.Marshaler或encoding.TextMarshaler的值包装为SensitiveDataMarshaler，在序列化时处理其结果；
+// 无法展开的其他值（标量等）保留原始值
+func (f *SensitiveDataFilter) maskReflected(value interface{}, depth int) interface{} {
+	converted, ok := reflectValue(value)
+	if !ok {
+		switch value.(type) {
+		case json.Marshaler, encoding.TextMarshaler:
+			if !isNilPointer(value) {
+				return &SensitiveDataMarshaler{Data: value, Filter: f}
+			}
+		}
+		return value
+	}
+	switch v := converted.(type) {
+	case map[string]interface{}:
+		return f.maskMapData(v, depth)
+	case []interface{}:
+		return f.maskSliceData(v, depth)
+	default:
+		return value
+	}
+}
+
 // SensitiveDataMarshaler 自定义JSON序列化器，用于在序列化过程中过滤敏感数据
 type SensitiveDataMarshaler struct {
 	Data   interface{}
@@ -119,7 +774,20 @@ type SensitiveDataMarshaler struct {
 }
 
 // MarshalJSON 实现json.Marshaler接口
-func (m *SensitiveDataMarshaler) MarshalJSON() ([]byte, error) {
+// 序列化过程中的panic（如自定义MarshalJSON触发的panic）会被恢复，输出到标准错误后作为错误返回
+func (m *SensitiveDataMarshaler) MarshalJSON() (data []byte, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			fmt.Fprintf(os.Stderr, "zaploggerfilter: recovered from panic while marshaling %T: %v\n", m.Data, r)
+			data, err = nil, fmt.Errorf("panic while marshaling %T: %v", m.Data, r)
+		}
+	}()
+
+	// 处理nil数据，包括类型化的nil指针（其方法可能在调用时panic）
+	if m.Data == nil || isNilPointer(m.Data) {
+		return []byte("null"), nil
+	}
+
 	// 处理nil过滤器
 	if m.Filter == nil {
 		return json.Marshal(m.Data)
@@ -133,52 +801,467 @@ func (m *SensitiveDataMarshaler) MarshalJSON() ([]byte, error) {
 		return json.Marshal(maskedData)
 	case []interface{}:
 		// 对于数组类型，直接处理
-		maskedSlice := m.Filter.maskSliceData(v)
+		maskedSlice := m.Filter.maskSliceData(v, 0)
 		return json.Marshal(maskedSlice)
+	case slog.LogValuer:
+		// 先解析为最终的值，再对解析结果进行掩码处理
+		resolved := &SensitiveDataMarshaler{Data: slogValue(slog.AnyValue(v).Resolve()), Filter: m.Filter}
+		return resolved.MarshalJSON()
+	case encoding.TextMarshaler:
+		// 文本序列化类型按JSON字符串处理，仅应用字段值匹配模式
+		// 同时实现了json.Marshaler的类型仍按其JSON结构处理
+		if _, ok := v.(json.Marshaler); !ok {
+			text, err := v.MarshalText()
+			if err != nil {
+				return nil, fmt.Errorf("failed to marshal text: %w", err)
+			}
+			masked, _ := m.Filter.maskString(string(text))
+			return json.Marshal(masked)
+		}
+		return m.marshalMasked()
 	default:
-		// 对于其他类型，先序列化为JSON，然后解析为map进行处理
-		jsonData, err := json.Marshal(m.Data)
+		return m.marshalMasked()
+	}
+}
+
+// slogValue 将已解析的slog.Value转换为普通的Go值，分组转换为map[string]interface{}
+func slogValue(v slog.Value) interface{} {
+	if v.Kind() != slog.KindGroup {
+		return v.Any()
+	}
+	attrs := v.Group()
+	group := make(map[string]interface{}, len(attrs))
+	for _, attr := range attrs {
+		group[attr.Key] = slogValue(attr.Value.Resolve())
+	}
+	return group
+}
+
+// marshalMasked 序列化数据并对结果进行掩码处理
+func (m *SensitiveDataMarshaler) marshalMasked() ([]byte, error) {
+	// 结构体、map和切片通过反射直接转换，保留字段的原始Go类型，对象的键按字典序输出
+	if converted, ok := reflectValue(m.Data); ok {
+		switch v := converted.(type) {
+		case map[string]interface{}:
+			return json.Marshal(m.Filter.maskMapData(v, 0))
+		case []interface{}:
+			return json.Marshal(m.Filter.maskSliceData(v, 0))
+		}
+	}
+
+	// 其他类型（如实现了json.Marshaler的类型）先序列化为JSON，再按原始键顺序流式处理
+	jsonData, err := json.Marshal(m.Data)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal data: %w", err)
+	}
+
+	result, err := m.Filter.maskJSON(jsonData)
+	if err != nil {
+		return nil, fmt.Errorf("failed to mask data: %w", err)
+	}
+	return result, nil
+}
+
+// maskJSON 对JSON数据进行掩码处理，保留对象中键的原始顺序
+// 数字按原样输出，不会因转换为float64而丢失精度
+func (f *SensitiveDataFilter) maskJSON(data []byte) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := f.maskJSONValue(newJSONDecoder(data), &buf); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// maskJSONValue 从解码器读取一个JSON值，掩码处理后写入buf
+func (f *SensitiveDataFilter) maskJSONValue(dec *json.Decoder, buf *bytes.Buffer) error {
+	tok, err := dec.Token()
+	if err != nil {
+		return err
+	}
+
+	switch v := tok.(type) {
+	case json.Delim:
+		if v == '{' {
+			return f.maskJSONObject(dec, buf)
+		}
+		// 数组
+		buf.WriteByte('[')
+		for i := 0; dec.More(); i++ {
+			if i > 0 {
+				buf.WriteByte(',')
+			}
+			if err = f.maskJSONValue(dec, buf); err != nil {
+				return err
+			}
+		}
+		if _, err = dec.Token(); err != nil {
+			return err
+		}
+		buf.WriteByte(']')
+		return nil
+	case string:
+		// 对字符串内容应用字段值匹配模式
+		masked, _ := f.maskString(v)
+		return writeJSON(buf, masked)
+	default:
+		// json.Number、bool、nil 保持原样
+		return writeJSON(buf, v)
+	}
+}
+
+// maskJSONObject 处理JSON对象，调用时左花括号已被读取
+// 开启省略零值时，按原始值省略零值的非敏感字段
+func (f *SensitiveDataFilter) maskJSONObject(dec *json.Decoder, buf *bytes.Buffer) error {
+	omitZero := f.OmitZeroValues()
+	buf.WriteByte('{')
+	for written := 0; dec.More(); {
+		tok, err := dec.Token()
 		if err != nil {
-			return nil, fmt.Errorf("failed to marshal data: %w", err)
-		}
-
-		// 尝试解析为map
-		var dataMap map[string]interface{}
-		err = json.Unmarshal(jsonData, &dataMap)
-		if err == nil {
-			// 掩码敏感字段
-			maskedData := m.Filter.MaskSensitiveData(dataMap)
-			// 重新序列化为JSON
-			result, marshalErr := json.Marshal(maskedData)
-			if marshalErr != nil {
-				return nil, fmt.Errorf("failed to marshal masked data: %w", marshalErr)
+			return err
+		}
+		key, _ := tok.(string)
+		sensitive := f.IsSensitiveField(key)
+
+		// 需要判断零值或掩码时先读取原始值
+		var raw json.RawMessage
+		if sensitive || omitZero {
+			if err = dec.Decode(&raw); err != nil {
+				return err
+			}
+			if !sensitive && isZeroJSON(raw) {
+				continue
+			}
+		}
+
+		if written > 0 {
+			buf.WriteByte(',')
+		}
+		written++
+		if err = writeJSON(buf, key); err != nil {
+			return err
+		}
+		buf.WriteByte(':')
+
+		if !sensitive {
+			if raw == nil {
+				err = f.maskJSONValue(dec, buf)
+			} else {
+				err = f.maskJSONValue(newJSONDecoder(raw), buf)
 			}
-			return result, nil
-		}
-
-		// 尝试解析为数组
-		var dataArray []interface{}
-		err = json.Unmarshal(jsonData, &dataArray)
-		if err == nil {
-			// 掩码数组中的敏感字段
-			maskedArray := m.Filter.maskSliceData(dataArray)
-			// 重新序列化为JSON
-			result, marshalErr := json.Marshal(maskedArray)
-			if marshalErr != nil {
-				return nil, fmt.Errorf("failed to marshal masked array: %w", marshalErr)
+			if err != nil {
+				return err
 			}
-			return result, nil
+			continue
+		}
+
+		// 敏感字段的原始值交由掩码函数或算法处理，数字保留为json.Number
+		var value interface{}
+		if err = newJSONDecoder(raw).Decode(&value); err != nil {
+			return err
 		}
+		if err = writeJSON(buf, f.maskValue(key, value)); err != nil {
+			return err
+		}
+	}
+	if _, err := dec.Token(); err != nil {
+		return err
+	}
+	buf.WriteByte('}')
+	return nil
+}
 
-		// 如果不是对象或数组类型，直接返回原始数据
-		return jsonData, nil
+// newJSONDecoder 创建将数字解码为json.Number的解码器
+func newJSONDecoder(data []byte) *json.Decoder {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	return dec
+}
+
+// isZeroJSON 判断JSON值是否为null、0、false或空字符串，对象和数组不视为零值
+func isZeroJSON(raw json.RawMessage) bool {
+	if len(raw) == 0 || raw[0] == '{' || raw[0] == '[' {
+		return false
 	}
+	var value interface{}
+	return newJSONDecoder(raw).Decode(&value) == nil && isZeroValue(value)
+}
+
+// writeJSON 将值序列化为JSON并写入buf
+func writeJSON(buf *bytes.Buffer, v interface{}) error {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	buf.Write(data)
+	return nil
 }
 
 // SensitiveDataEncoder 集成了敏感数据过滤功能的zap编码器
+// 过滤在EncodeEntry中进行，直接添加到编码器中的字段（如zapcore.NewCore的With添加的字段）在Add*方法中过滤，
+// 因此编码器需要由日志核心包装（而不是反过来），
+// 级别检查（包括Check模式）由日志核心负责，所有经由该核心写入的条目都会被过滤：
+//
+//	encoder := &SensitiveDataEncoder{
+//		Encoder: zapcore.NewJSONEncoder(cfg),
+//		Filter:  NewSensitiveDataFilter([]string{"password"}),
+//	}
+//	core := zapcore.NewCore(encoder, zapcore.AddSync(os.Stdout), zapcore.InfoLevel)
+//	logger := zap.New(core).With(zap.String("password", "secret")) // 输出 "password":"***"
+//
+// 注意不要将内部编码器直接传给zapcore.NewCore，也不要在包装后继续使用原编码器
+// 编码器不实现zapcore.LevelEnabler，需要额外的级别限制时使用NewLevelBoundedCore包装日志核心
 type SensitiveDataEncoder struct {
 	zapcore.Encoder
 	Filter *SensitiveDataFilter
+	// AuditMode 审计模式，开启后敏感字段保留原始值，并追加 key+"_masked": true 的标记字段
+	// 可配合MaskedOnlyEncoder生成脱敏后的日志流
+	AuditMode bool
+	// namespace 通过With添加的zap.Namespace打开的命名空间路径
+	namespace string
+}
+
+// NewConsoleEncoderWithFilter 创建使用filter过滤敏感数据的控制台格式编码器，可直接传给zapcore.NewCore
+// filter为nil时不过滤任何字段
+func NewConsoleEncoderWithFilter(encoderConfig zapcore.EncoderConfig, filter *SensitiveDataFilter) zapcore.Encoder {
+	return &SensitiveDataEncoder{
+		Encoder: zapcore.NewConsoleEncoder(encoderConfig),
+		Filter:  filter,
+	}
+}
+
+// NewJSONEncoderWithFilter 创建使用filter过滤敏感数据的JSON格式编码器，可直接传给zapcore.NewCore
+// filter为nil时不过滤任何字段
+func NewJSONEncoderWithFilter(encoderConfig zapcore.EncoderConfig, filter *SensitiveDataFilter) zapcore.Encoder {
+	return &SensitiveDataEncoder{
+		Encoder: zapcore.NewJSONEncoder(encoderConfig),
+		Filter:  filter,
+	}
+}
+
+// Clone 实现zapcore.Encoder接口
+// 复制内部编码器的同时保留过滤器，避免通过With等方式派生的日志核心丢失敏感数据过滤
+func (e *SensitiveDataEncoder) Clone() zapcore.Encoder {
+	return &SensitiveDataEncoder{
+		Encoder:   e.Encoder.Clone(),
+		Filter:    e.Filter,
+		AuditMode: e.AuditMode,
+		namespace: e.namespace,
+	}
+}
+
+// fieldEncoder 获取直接添加到编码器中的字段（如zapcore.NewCore的With添加的字段）使用的对象编码器
+// 返回nil时丢弃该字段；audited为true时写入字段后需追加审计标记
+func (e *SensitiveDataEncoder) fieldEncoder(key string) (enc zapcore.ObjectEncoder, audited bool) {
+	if e.Filter == nil {
+		return e.Encoder, false
+	}
+	if !e.AuditMode {
+		return &filteringObjectEncoder{ObjectEncoder: e.Encoder, filter: e.Filter, namespace: e.namespace}, false
+	}
+	if _, ok := e.Filter.sensitiveKey(key, e.namespace); !ok {
+		return e.Encoder, false
+	}
+	// MaskedOnlyEncoder无法丢弃已写入的字段，审计模式下的敏感字段直接丢弃
+	if _, ok := e.Encoder.(*MaskedOnlyEncoder); ok {
+		return nil, false
+	}
+	return e.Encoder, true
+}
+
+// markAudited 审计模式下在敏感字段之后追加标记字段
+func (e *SensitiveDataEncoder) markAudited(key string, audited bool) {
+	if audited {
+		e.Encoder.AddBool(key+auditMarkerSuffix, true)
+	}
+}
+
+// OpenNamespace 实现zapcore.ObjectEncoder接口，记录命名空间以匹配带命名空间前缀的敏感字段
+func (e *SensitiveDataEncoder) OpenNamespace(key string) {
+	e.namespace = joinNamespace(e.namespace, key)
+	e.Encoder.OpenNamespace(key)
+}
+
+func (e *SensitiveDataEncoder) AddArray(key string, value zapcore.ArrayMarshaler) error {
+	enc, audited := e.fieldEncoder(key)
+	if enc == nil {
+		return nil
+	}
+	if err := enc.AddArray(key, value); err != nil {
+		return err
+	}
+	e.markAudited(key, audited)
+	return nil
+}
+
+func (e *SensitiveDataEncoder) AddObject(key string, value zapcore.ObjectMarshaler) error {
+	enc, audited := e.fieldEncoder(key)
+	if enc == nil {
+		return nil
+	}
+	if err := enc.AddObject(key, value); err != nil {
+		return err
+	}
+	e.markAudited(key, audited)
+	return nil
+}
+
+func (e *SensitiveDataEncoder) AddReflected(key string, value interface{}) error {
+	enc, audited := e.fieldEncoder(key)
+	if enc == nil {
+		return nil
+	}
+	if err := enc.AddReflected(key, value); err != nil {
+		return err
+	}
+	e.markAudited(key, audited)
+	return nil
+}
+
+func (e *SensitiveDataEncoder) AddBinary(key string, value []byte) {
+	if enc, audited := e.fieldEncoder(key); enc != nil {
+		enc.AddBinary(key, value)
+		e.markAudited(key, audited)
+	}
+}
+
+func (e *SensitiveDataEncoder) AddByteString(key string, value []byte) {
+	if enc, audited := e.fieldEncoder(key); enc != nil {
+		enc.AddByteString(key, value)
+		e.markAudited(key, audited)
+	}
+}
+
+func (e *SensitiveDataEncoder) AddBool(key string, value bool) {
+	if enc, audited := e.fieldEncoder(key); enc != nil {
+		enc.AddBool(key, value)
+		e.markAudited(key, audited)
+	}
+}
+
+func (e *SensitiveDataEncoder) AddComplex128(key string, value complex128) {
+	if enc, audited := e.fieldEncoder(key); enc != nil {
+		enc.AddComplex128(key, value)
+		e.markAudited(key, audited)
+	}
+}
+
+func (e *SensitiveDataEncoder) AddComplex64(key string, value complex64) {
+	if enc, audited := e.fieldEncoder(key); enc != nil {
+		enc.AddComplex64(key, value)
+		e.markAudited(key, audited)
+	}
+}
+
+func (e *SensitiveDataEncoder) AddDuration(key string, value time.Duration) {
+	if enc, audited := e.fieldEncoder(key); enc != nil {
+		enc.AddDuration(key, value)
+		e.markAudited(key, audited)
+	}
+}
+
+func (e *SensitiveDataEncoder) AddFloat64(key string, value float64) {
+	if enc, audited := e.fieldEncoder(key); enc != nil {
+		enc.AddFloat64(key, value)
+		e.markAudited(key, audited)
+	}
+}
+
+func (e *SensitiveDataEncoder) AddFloat32(key string, value float32) {
+	if enc, audited := e.fieldEncoder(key); enc != nil {
+		enc.AddFloat32(key, value)
+		e.markAudited(key, audited)
+	}
+}
+
+func (e *SensitiveDataEncoder) AddInt(key string, value int) {
+	if enc, audited := e.fieldEncoder(key); enc != nil {
+		enc.AddInt(key, value)
+		e.markAudited(key, audited)
+	}
+}
+
+func (e *SensitiveDataEncoder) AddInt64(key string, value int64) {
+	if enc, audited := e.fieldEncoder(key); enc != nil {
+		enc.AddInt64(key, value)
+		e.markAudited(key, audited)
+	}
+}
+
+func (e *SensitiveDataEncoder) AddInt32(key string, value int32) {
+	if enc, audited := e.fieldEncoder(key); enc != nil {
+		enc.AddInt32(key, value)
+		e.markAudited(key, audited)
+	}
+}
+
+func (e *SensitiveDataEncoder) AddInt16(key string, value int16) {
+	if enc, audited := e.fieldEncoder(key); enc != nil {
+		enc.AddInt16(key, value)
+		e.markAudited(key, audited)
+	}
+}
+
+func (e *SensitiveDataEncoder) AddInt8(key string, value int8) {
+	if enc, audited := e.fieldEncoder(key); enc != nil {
+		enc.AddInt8(key, value)
+		e.markAudited(key, audited)
+	}
+}
+
+func (e *SensitiveDataEncoder) AddString(key string, value string) {
+	if enc, audited := e.fieldEncoder(key); enc != nil {
+		enc.AddString(key, value)
+		e.markAudited(key, audited)
+	}
+}
+
+func (e *SensitiveDataEncoder) AddTime(key string, value time.Time) {
+	if enc, audited := e.fieldEncoder(key); enc != nil {
+		enc.AddTime(key, value)
+		e.markAudited(key, audited)
+	}
+}
+
+func (e *SensitiveDataEncoder) AddUint(key string, value uint) {
+	if enc, audited := e.fieldEncoder(key); enc != nil {
+		enc.AddUint(key, value)
+		e.markAudited(key, audited)
+	}
+}
+
+func (e *SensitiveDataEncoder) AddUint64(key string, value uint64) {
+	if enc, audited := e.fieldEncoder(key); enc != nil {
+		enc.AddUint64(key, value)
+		e.markAudited(key, audited)
+	}
+}
+
+func (e *SensitiveDataEncoder) AddUint32(key string, value uint32) {
+	if enc, audited := e.fieldEncoder(key); enc != nil {
+		enc.AddUint32(key, value)
+		e.markAudited(key, audited)
+	}
+}
+
+func (e *SensitiveDataEncoder) AddUint16(key string, value uint16) {
+	if enc, audited := e.fieldEncoder(key); enc != nil {
+		enc.AddUint16(key, value)
+		e.markAudited(key, audited)
+	}
+}
+
+func (e *SensitiveDataEncoder) AddUint8(key string, value uint8) {
+	if enc, audited := e.fieldEncoder(key); enc != nil {
+		enc.AddUint8(key, value)
+		e.markAudited(key, audited)
+	}
+}
+
+func (e *SensitiveDataEncoder) AddUintptr(key string, value uintptr) {
+	if enc, audited := e.fieldEncoder(key); enc != nil {
+		enc.AddUintptr(key, value)
+		e.markAudited(key, audited)
+	}
 }
 
 // EncodeEntry 重写编码方法，在编码过程中过滤敏感字段
@@ -188,6 +1271,11 @@ func (e *SensitiveDataEncoder) EncodeEntry(ent zapcore.Entry, fields []zapcore.F
 		return e.Encoder.EncodeEntry(ent, fields)
 	}
 
+	// 开启日志消息掩码时，对日志消息应用字段值匹配模式
+	if e.Filter.MessageMasking() {
+		ent.Message = e.Filter.maskMessage(ent.Message)
+	}
+
 	// 处理空字段列表
 	if len(fields) == 0 {
 		return e.Encoder.EncodeEntry(ent, fields)
@@ -196,28 +1284,251 @@ func (e *SensitiveDataEncoder) EncodeEntry(ent zapcore.Entry, fields []zapcore.F
 	// 预分配过滤后的字段列表，容量至少为原始字段数
 	filteredFields := make([]zapcore.Field, 0, len(fields))
 
+	// namespace 当前字段所属的命名空间，从With打开的命名空间开始，由字段列表中的NamespaceType字段累积得到
+	namespace := e.namespace
+	// namespaceIndex 第一个NamespaceType字段在过滤后字段列表中的位置，审计记录字段插入在此之前以保持在顶层
+	namespaceIndex := -1
+
+	trail, trailKey := e.Filter.auditTrailSettings()
+	var redacted []string
+
 	// 检查并替换敏感字段
 	for _, field := range fields {
-		// 转换键为小写进行比较
-		lowerKey := strings.ToLower(field.Key)
-
-		// 检查字段名是否为敏感字段
-		if e.Filter.IsSensitiveField(lowerKey) {
-			// 敏感字段直接替换为掩码字符串
-			filteredFields = append(filteredFields, zap.String(field.Key, Mask))
-		} else if (field.Type == zapcore.ReflectType || field.Type == zapcore.ObjectMarshalerType) && field.Interface != nil {
-			// 对于复杂类型，使用自定义序列化器处理
-			marshaler := &SensitiveDataMarshaler{
-				Data:   field.Interface,
-				Filter: e.Filter,
+		if field.Type == zapcore.SkipType {
+			// 占位字段不会输出任何内容，直接保留
+			filteredFields = append(filteredFields, field)
+			continue
+		}
+		if field.Type == zapcore.NamespaceType {
+			if namespaceIndex < 0 {
+				namespaceIndex = len(filteredFields)
 			}
-			filteredFields = append(filteredFields, zap.Any(field.Key, marshaler))
-		} else {
-			// 其他字段保持不变
+			namespace = joinNamespace(namespace, field.Key)
 			filteredFields = append(filteredFields, field)
+			continue
+		}
+
+		filtered, masked := e.Filter.filterField(field, namespace)
+		// 复杂类型的值在序列化时才进行掩码处理，需要额外判断是否有内容被掩码
+		if !masked && (e.AuditMode || trail) && isComplexField(field) {
+			masked = e.Filter.complexValueMasked(field.Interface)
+		}
+		if trail && masked {
+			redacted = append(redacted, joinNamespace(namespace, field.Key))
+		}
+
+		// TracedError的堆栈和附加字段追加在错误字段之后
+		traced := e.Filter.tracedErrorFields(field, !e.AuditMode)
+		if !e.AuditMode {
+			filteredFields = append(filteredFields, filtered)
+			filteredFields = append(filteredFields, traced...)
+			continue
+		}
+
+		// 审计模式下保留原始值，仅对被掩码的字段追加标记
+		filteredFields = append(filteredFields, field)
+		if masked {
+			filteredFields = append(filteredFields, zap.Bool(field.Key+auditMarkerSuffix, true))
+		}
+		filteredFields = append(filteredFields, traced...)
+	}
+
+	// 有字段被掩码时追加审计记录字段
+	if len(redacted) > 0 {
+		report := zap.Strings(trailKey, redacted)
+		if namespaceIndex >= 0 {
+			filteredFields = slices.Insert(filteredFields, namespaceIndex, report)
+		} else {
+			filteredFields = append(filteredFields, report)
 		}
 	}
 
 	// 使用原始编码器进行编码
 	return e.Encoder.EncodeEntry(ent, filteredFields)
 }
+
+// FilterFields 使用与SensitiveDataEncoder相同的规则过滤字段列表，返回新的字段列表，不会修改原字段列表
+// 可在将字段传给自定义的zapcore.Core或zap.Logger.With之前使用。过滤器为nil时原样返回
+func (f *SensitiveDataFilter) FilterFields(fields []zapcore.Field) []zapcore.Field {
+	return f.filterFields(fields)
+}
+
+// filterFields 过滤字段列表，返回新的字段列表
+// 过滤器为nil时原样返回
+func (f *SensitiveDataFilter) filterFields(fields []zapcore.Field) []zapcore.Field {
+	return f.filterFieldsIn(fields, "")
+}
+
+// filterFieldsIn 过滤命名空间namespace中的字段列表，见filterFields
+func (f *SensitiveDataFilter) filterFieldsIn(fields []zapcore.Field, namespace string) []zapcore.Field {
+	if f == nil || len(fields) == 0 {
+		return fields
+	}
+
+	filteredFields := make([]zapcore.Field, 0, len(fields))
+	for _, field := range fields {
+		if field.Type == zapcore.SkipType {
+			// 占位字段不会输出任何内容，直接保留
+			filteredFields = append(filteredFields, field)
+			continue
+		}
+		if field.Type == zapcore.NamespaceType {
+			namespace = joinNamespace(namespace, field.Key)
+			filteredFields = append(filteredFields, field)
+			continue
+		}
+		filtered, _ := f.filterField(field, namespace)
+		filteredFields = append(filteredFields, filtered)
+		filteredFields = append(filteredFields, f.tracedErrorFields(field, true)...)
+	}
+	return filteredFields
+}
+
+// joinNamespace 拼接命名空间路径
+func joinNamespace(namespace, key string) string {
+	if namespace == "" {
+		return key
+	}
+	return namespace + "." + key
+}
+
+// sensitiveKey 检查字段是否为敏感字段
+// 字段名本身或带命名空间前缀的完整路径（如 "payment.card"）匹配时均视为敏感字段
+// 返回: 匹配的字段名，以及是否为敏感字段
+func (f *SensitiveDataFilter) sensitiveKey(key, namespace string) (string, bool) {
+	if f.IsSensitiveField(key) {
+		return key, true
+	}
+	if namespace != "" {
+		if qualified := joinNamespace(namespace, key); f.IsSensitiveField(qualified) {
+			return qualified, true
+		}
+	}
+	return "", false
+}
+
+// filterField 过滤单个字段
+// namespace: 字段所属的命名空间，为空表示顶层字段
+// 返回: 过滤后的字段，以及字段是否因字段名或字段值匹配而被掩码
+// 复杂类型字段会被包装为SensitiveDataMarshaler，其内容在序列化时才会被处理
+func (f *SensitiveDataFilter) filterField(field zapcore.Field, namespace string) (zapcore.Field, bool) {
+	// 检查字段名是否为敏感字段
+	if key, ok := f.sensitiveKey(field.Key, namespace); ok {
+		// 敏感字段直接替换为掩码字符串
+		var value interface{}
+		if field.Type == zapcore.StringType {
+			value = field.String
+		} else if f.hasFieldMaskFunc(key) {
+			// 字段的掩码函数需要其他类型字段的原始值
+			value = zapFieldValue(field)
+		}
+		return zap.Any(field.Key, f.maskValue(key, value)), true
+	}
+
+	if field.Type == zapcore.StringType {
+		// 字符串字段检查字段值匹配模式
+		if masked, changed := f.maskString(field.String); changed {
+			return zap.String(field.Key, masked), true
+		}
+		return field, false
+	}
+
+	if field.Type == zapcore.ErrorType {
+		// 错误信息可能包含敏感数据（如数据库连接串中的密码）
+		if err, ok := field.Interface.(error); ok && f.errorChainMatches(err, 0) {
+			masked, _ := f.maskString(err.Error())
+			return zap.NamedError(field.Key, errors.New(masked)), true
+		}
+		return field, false
+	}
+
+	if field.Type == zapcore.StringerType {
+		// fmt.Stringer字段检查其字符串值是否匹配字段值模式
+		if str, ok := stringerValue(field.Interface); ok {
+			if masked, changed := f.maskString(str); changed {
+				return zap.String(field.Key, masked), true
+			}
+		}
+		return field, false
+	}
+
+	if field.Type == zapcore.ArrayMarshalerType {
+		// 数组字段在MarshalLogArray过程中逐个元素处理
+		if arr, ok := field.Interface.(zapcore.ArrayMarshaler); ok {
+			return zap.Array(field.Key, &SensitiveArrayMarshaler{Arr: arr, Filter: f}), false
+		}
+		return field, false
+	}
+
+	if field.Type == zapcore.InlineMarshalerType {
+		// 内联对象的字段直接写入当前层级，逐个字段处理
+		if obj, ok := field.Interface.(zapcore.ObjectMarshaler); ok {
+			return zap.Inline(&FilteredInlineMarshaler{Obj: obj, Filter: f, namespace: namespace}), false
+		}
+		return field, false
+	}
+
+	if isComplexField(field) {
+		// 对于复杂类型，使用自定义序列化器处理
+		marshaler := &SensitiveDataMarshaler{
+			Data:   field.Interface,
+			Filter: f,
+		}
+		return zap.Any(field.Key, marshaler), false
+	}
+
+	// 其他字段保持不变
+	return field, false
+}
+
+// maxErrorChainDepth 检查错误链的最大深度
+const maxErrorChainDepth = 32
+
+// errorChainMatches 检查错误及其包装链（errors.Unwrap）中是否有错误信息匹配字段值模式
+func (f *SensitiveDataFilter) errorChainMatches(err error, depth int) bool {
+	if err == nil || depth > maxErrorChainDepth {
+		return false
+	}
+	if _, changed := f.maskString(err.Error()); changed {
+		return true
+	}
+
+	switch wrapped := err.(type) {
+	case interface{ Unwrap() error }:
+		return f.errorChainMatches(wrapped.Unwrap(), depth+1)
+	case interface{ Unwrap() []error }:
+		for _, inner := range wrapped.Unwrap() {
+			if f.errorChainMatches(inner, depth+1) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// stringerValue 安全地获取fmt.Stringer的字符串值
+// String方法panic（如nil指针接收者）时返回false，由zap按原有方式处理该字段
+func stringerValue(v interface{}) (str string, ok bool) {
+	stringer, isStringer := v.(fmt.Stringer)
+	if !isStringer {
+		return "", false
+	}
+	defer func() {
+		if recover() != nil {
+			str, ok = "", false
+		}
+	}()
+	return stringer.String(), true
+}
+
+// zapFieldValue 获取字段的原始值，如数字、布尔值或反射字段的值
+func zapFieldValue(field zapcore.Field) interface{} {
+	enc := zapcore.NewMapObjectEncoder()
+	field.AddTo(enc)
+	return enc.Fields[field.Key]
+}
+
+// isComplexField 判断字段是否为需要序列化处理的复杂类型
+func isComplexField(field zapcore.Field) bool {
+	return (field.Type == zapcore.ReflectType || field.Type == zapcore.ObjectMarshalerType) && field.Interface != nil
+}
@@ -0,0 +1,309 @@
+package zaploggerfilter
+
+import (
+	"encoding/json"
+	"strings"
+	"sync"
+	"testing"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// newFilteredCore 创建使用SensitiveDataEncoder并写入buf的日志核心
+func newFilteredCore(buf *syncBuffer, level zapcore.LevelEnabler, fields ...string) zapcore.Core {
+	encoder := &SensitiveDataEncoder{
+		Encoder: zapcore.NewJSONEncoder(zapcore.EncoderConfig{MessageKey: "msg", LevelKey: "level", EncodeLevel: zapcore.LowercaseLevelEncoder}),
+		Filter:  NewSensitiveDataFilter(fields),
+	}
+	return zapcore.NewCore(encoder, buf, level)
+}
+
+func TestSensitiveDataEncoderLevelGating(t *testing.T) {
+	var encoder zapcore.Encoder = &SensitiveDataEncoder{Encoder: zapcore.NewJSONEncoder(zapcore.EncoderConfig{})}
+	if _, ok := encoder.(zapcore.LevelEnabler); ok {
+		t.Fatal("SensitiveDataEncoder must not implement zapcore.LevelEnabler, levels are gated by the core")
+	}
+
+	var buf syncBuffer
+	level := zap.NewAtomicLevelAt(zapcore.InfoLevel)
+	lg := zap.New(newFilteredCore(&buf, level, "password"))
+
+	if ce := lg.Check(zapcore.DebugLevel, "debug"); ce != nil {
+		t.Error("Check(DebugLevel) returned an entry below the core level")
+	}
+	lg.Debug("debug", zap.String("password", "secret"))
+	if ce := lg.Check(zapcore.InfoLevel, "info"); ce == nil {
+		t.Fatal("Check(InfoLevel) returned nil")
+	} else {
+		ce.Write(zap.String("password", "secret"))
+	}
+	lg.Error("error", zap.String("password", "secret"))
+
+	got := buf.String()
+	if strings.Contains(got, `"msg":"debug"`) {
+		t.Errorf("debug entry written below the core level: %q", got)
+	}
+	for _, want := range []string{`{"level":"info","msg":"info","password":"***"}`, `{"level":"error","msg":"error","password":"***"}`} {
+		if !strings.Contains(got, want) {
+			t.Errorf("output = %q, want %s", got, want)
+		}
+	}
+	if strings.Contains(got, "secret") {
+		t.Errorf("sensitive value written: %q", got)
+	}
+
+	// 修改日志核心的动态级别后立即生效
+	level.SetLevel(zapcore.DebugLevel)
+	lg.Debug("enabled", zap.String("password", "secret"))
+	if got := buf.String(); !strings.Contains(got, `{"level":"debug","msg":"enabled","password":"***"}`) {
+		t.Errorf("output = %q, want debug entry after lowering the level", got)
+	}
+}
+
+func TestSensitiveDataEncoderLevelBoundedCore(t *testing.T) {
+	var buf syncBuffer
+	core := NewLevelBoundedCore(newFilteredCore(&buf, zapcore.DebugLevel, "token"), zapcore.WarnLevel, zapcore.ErrorLevel)
+	lg := zap.New(core)
+
+	lg.Info("info", zap.String("token", "abc"))
+	lg.Warn("warn", zap.String("token", "abc"))
+	lg.DPanic("dpanic", zap.String("token", "abc"))
+
+	got := buf.String()
+	if strings.Contains(got, `"msg":"info"`) || strings.Contains(got, `"msg":"dpanic"`) {
+		t.Errorf("entry outside the level range written: %q", got)
+	}
+	if !strings.Contains(got, `{"level":"warn","msg":"warn","token":"***"}`) {
+		t.Errorf("output = %q, want masked warn entry", got)
+	}
+}
+
+// fixedMask 将值替换为固定字符串的掩码算法
+type fixedMask string
+
+// Mask 实现MaskingAlgorithm接口
+func (m fixedMask) Mask(string) string {
+	return string(m)
+}
+
+func TestSensitiveDataFilterCloneConcurrent(t *testing.T) {
+	base := NewSensitiveDataFilter([]string{"password", "token"})
+	base.SetFieldMaskAlgorithm("token", fixedMask("[token]"))
+
+	var buf syncBuffer
+	lg := zap.New(zapcore.NewCore(&SensitiveDataEncoder{
+		Encoder: zapcore.NewJSONEncoder(zapcore.EncoderConfig{MessageKey: "msg"}),
+		Filter:  base,
+	}, &buf, zapcore.DebugLevel))
+
+	const workers, iterations = 4, 200
+	clones := make([]*SensitiveDataFilter, workers)
+	for i := range clones {
+		clones[i] = base.Clone()
+	}
+
+	var wg sync.WaitGroup
+	for _, clone := range clones {
+		wg.Add(2)
+		// 修改克隆
+		go func(clone *SensitiveDataFilter) {
+			defer wg.Done()
+			for j := 0; j < iterations; j++ {
+				clone.AddField("card_number")
+				clone.RemoveField("password")
+				clone.AddAlias("pwd", "token")
+				clone.SetFieldMaskAlgorithm("token", fixedMask("[clone]"))
+				clone.SetFieldMaskFunc("ssn", func(interface{}) interface{} { return "***-**-****" })
+				clone.MaskSensitiveData(map[string]interface{}{"card_number": "4111", "password": "x"})
+			}
+		}(clone)
+		// 同时使用原过滤器
+		go func() {
+			defer wg.Done()
+			for j := 0; j < iterations; j++ {
+				if !base.IsSensitiveField("password") || base.IsSensitiveField("card_number") {
+					t.Error("original filter changed by a clone")
+					return
+				}
+				lg.Info("login", zap.String("password", "secret"), zap.String("card_number", "4111"), zap.String("ssn", "123"))
+				base.Clone()
+			}
+		}()
+	}
+	wg.Wait()
+
+	masked := base.MaskSensitiveData(map[string]interface{}{"password": "x", "token": "y", "pwd": "z", "card_number": "4111", "ssn": "123"})
+	want := map[string]interface{}{"password": "***", "token": "[token]", "pwd": "z", "card_number": "4111", "ssn": "123"}
+	for key, value := range want {
+		if masked[key] != value {
+			t.Errorf("original MaskSensitiveData()[%q] = %v, want %v", key, masked[key], value)
+		}
+	}
+	if got := buf.String(); strings.Contains(got, "secret") || !strings.Contains(got, `"card_number":"4111"`) {
+		t.Errorf("original encoder output = %q, want only password masked", got[:min(len(got), 200)])
+	}
+
+	for i, clone := range clones {
+		masked := clone.MaskSensitiveData(map[string]interface{}{"password": "x", "pwd": "z", "card_number": "4111", "ssn": "123"})
+		want := map[string]interface{}{"password": "x", "pwd": "[clone]", "card_number": "***", "ssn": "***-**-****"}
+		for key, value := range want {
+			if masked[key] != value {
+				t.Errorf("clone %d MaskSensitiveData()[%q] = %v, want %v", i, key, masked[key], value)
+			}
+		}
+	}
+}
+
+func TestSensitiveDataMarshalerFieldMaskFunc(t *testing.T) {
+	filter := NewSensitiveDataFilter(nil)
+	var got interface{}
+	filter.SetFieldMaskFunc("balance", func(value interface{}) interface{} {
+		got = value
+		// 掩码函数中调用过滤器的设置方法不应死锁
+		filter.SetMaskAlgorithm(nil)
+		return "masked"
+	})
+
+	tests := []struct {
+		name string
+		data interface{}
+		want interface{}
+	}{
+		{
+			name: "struct keeps go type",
+			data: struct {
+				Balance int64 `json:"balance"`
+			}{Balance: 9007199254740993},
+			want: int64(9007199254740993),
+		},
+		{
+			name: "json marshaler decodes numbers",
+			data: json.RawMessage(`{"balance":9007199254740993}`),
+			want: json.Number("9007199254740993"),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got = nil
+			data, err := (&SensitiveDataMarshaler{Data: tt.data, Filter: filter}).MarshalJSON()
+			if err != nil {
+				t.Fatalf("MarshalJSON() error = %v", err)
+			}
+			if string(data) != `{"balance":"masked"}` {
+				t.Errorf("MarshalJSON() = %s", data)
+			}
+			if got != tt.want {
+				t.Errorf("mask func got %#v, want %#v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSensitiveDataMarshalerNestedMarshaler(t *testing.T) {
+	filter := NewSensitiveDataFilter([]string{"password"})
+	data, err := (&SensitiveDataMarshaler{
+		Data: struct {
+			Name  string          `json:"name"`
+			Inner json.RawMessage `json:"inner"`
+		}{Name: "alice", Inner: json.RawMessage(`{"password":"secret"}`)},
+		Filter: filter,
+	}).MarshalJSON()
+	if err != nil {
+		t.Fatalf("MarshalJSON() error = %v", err)
+	}
+	if want := `{"inner":{"password":"***"},"name":"alice"}`; string(data) != want {
+		t.Errorf("MarshalJSON() = %s, want %s", data, want)
+	}
+}
+
+func TestSensitiveDataEncoderWithFields(t *testing.T) {
+	encCfg := zapcore.EncoderConfig{MessageKey: "msg"}
+	tests := []struct {
+		name    string
+		encoder func(f *SensitiveDataFilter) zapcore.Encoder
+		want    string
+	}{
+		{
+			name:    "json",
+			encoder: func(f *SensitiveDataFilter) zapcore.Encoder { return NewJSONEncoderWithFilter(encCfg, f) },
+			want:    `{"msg":"x","password":"***","user":"alice"}` + "\n",
+		},
+		{
+			name: "audit",
+			encoder: func(f *SensitiveDataFilter) zapcore.Encoder {
+				return &SensitiveDataEncoder{Encoder: zapcore.NewJSONEncoder(encCfg), Filter: f, AuditMode: true}
+			},
+			want: `{"msg":"x","password":"hunter2","password_masked":true,"user":"alice"}` + "\n",
+		},
+		{
+			name: "masked only",
+			encoder: func(f *SensitiveDataFilter) zapcore.Encoder {
+				return &SensitiveDataEncoder{Encoder: &MaskedOnlyEncoder{Encoder: zapcore.NewJSONEncoder(encCfg)}, Filter: f, AuditMode: true}
+			},
+			want: `{"msg":"x","user":"alice"}` + "\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf syncBuffer
+			filter := NewSensitiveDataFilter([]string{"password"})
+			logger := zap.New(zapcore.NewCore(tt.encoder(filter), &buf, zapcore.DebugLevel))
+			logger.With(zap.String("password", "hunter2"), zap.String("user", "alice")).Info("x")
+
+			if got := buf.String(); got != tt.want {
+				t.Errorf("output = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestWithFieldsMaskedOnce(t *testing.T) {
+	var buf syncBuffer
+	initTestLoggers(t, bufferConfig("app", &buf, "password"))
+	nl, _ := loadLogger("app")
+
+	var calls int
+	nl.filter.SetFieldMaskFunc("phone", func(value interface{}) interface{} {
+		calls++
+		return "****" + value.(string)[7:]
+	})
+	lg, _ := GetTargetLogger("app")
+	lg.With(zap.String("phone", "13800001234")).Info("x")
+
+	if calls != 1 {
+		t.Errorf("mask func called %d times, want 1", calls)
+	}
+	if !strings.Contains(buf.String(), "****1234") || strings.Contains(buf.String(), "13800001234") {
+		t.Errorf("output = %s", buf.String())
+	}
+}
+
+func TestNormalizeFieldNameUnicode(t *testing.T) {
+	tests := []struct {
+		name string
+		want string
+	}{
+		{"STRAßE", "strasse"},
+		{"STRASSE", "strasse"},
+		{"straße", "strasse"},
+		// 与语言无关的大小写折叠不使用土耳其语规则
+		{"İD", "i̇d"},
+		{"ID", "id"},
+		{"ıd", "ıd"},
+	}
+	for _, tt := range tests {
+		if got := normalizeFieldName(tt.name); got != tt.want {
+			t.Errorf("normalizeFieldName(%q) = %q, want %q", tt.name, got, tt.want)
+		}
+	}
+
+	filter := NewSensitiveDataFilter([]string{"straße"})
+	if !filter.IsSensitiveField("STRASSE") {
+		t.Error(`IsSensitiveField("STRASSE") = false, want true for the sensitive field "straße"`)
+	}
+	filter = NewSensitiveDataFilter([]string{"id"})
+	if filter.IsSensitiveField("İD") {
+		t.Error(`IsSensitiveField("İD") = true, want false without Turkish case rules`)
+	}
+}
@@ -0,0 +1,285 @@
+// Package splunksink 提供向Splunk HEC（HTTP Event Collector）发送日志的日志核心
+// 导入该包后即可在zaploggerfilter的配置中使用Splunk类型：
+//
+//	import _ "github.com/november4bin/zap-logger-filter/splunksink"
+package splunksink
+
+import (
+	"bytes"
+	"crypto/tls"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"sync"
+	"time"
+
+	zaploggerfilter "github.com/november4bin/zap-logger-filter"
+	"go.uber.org/zap/zapcore"
+)
+
+func init() {
+	zaploggerfilter.RegisterEncoderCoreBuilder(zaploggerfilter.Splunk, func(cfg zaploggerfilter.Config, enc zapcore.Encoder, level zapcore.LevelEnabler) (zapcore.Core, error) {
+		return newCore(enc, cfg.HECUrl, cfg.HECToken, cfg.SplunkIndex, cfg.Source, level, Options{
+			InsecureSkipVerify: cfg.SplunkInsecureSkipVerify,
+		})
+	})
+}
+
+const (
+	// defaultSplunkBatchSize 默认每批发送的事件数
+	defaultSplunkBatchSize = 100
+	// defaultSplunkFlushInterval 默认的发送间隔
+	defaultSplunkFlushInterval = 5 * time.Second
+	// defaultSplunkTimeout 默认的请求超时时间
+	defaultSplunkTimeout = 10 * time.Second
+)
+
+// Options Splunk HEC日志核心的选项
+type Options struct {
+	// BatchSize 缓存的事件数达到该值时立即发送，默认为100
+	BatchSize int
+	// FlushInterval 缓存的事件最多等待该时间后发送，默认为5秒
+	FlushInterval time.Duration
+	// InsecureSkipVerify 跳过TLS证书校验，仅用于测试环境或自签名证书
+	InsecureSkipVerify bool
+	// Client 发送请求的HTTP客户端，设置后InsecureSkipVerify不生效
+	Client *http.Client
+}
+
+// NewCore 创建向Splunk HEC（HTTP Event Collector）发送日志的日志核心
+// hecURL为完整的事件接口地址，如 https://splunk:8088/services/collector/event
+// 事件按数量或时间间隔批量发送，Sync会立即发送缓存的事件
+// 返回的日志核心实现io.Closer，不再使用时需调用Close；通过配置创建的日志核心由Reset等关闭
+func NewCore(hecURL, token string, index, source string, level zapcore.Level) (zapcore.Core, error) {
+	return NewCoreWithOptions(hecURL, token, index, source, level, Options{})
+}
+
+// NewCoreWithOptions 使用选项创建Splunk HEC日志核心，见NewCore
+func NewCoreWithOptions(hecURL, token string, index, source string, level zapcore.Level, opts Options) (zapcore.Core, error) {
+	return newCore(zapcore.NewJSONEncoder(zaploggerfilter.DefaultEncoderConfig()), hecURL, token, index, source, level, opts)
+}
+
+// newCore 使用指定的编码器创建Splunk HEC日志核心，编码器需输出JSON对象
+func newCore(encoder zapcore.Encoder, hecURL, token string, index, source string, level zapcore.LevelEnabler, opts Options) (zapcore.Core, error) {
+	u, err := url.Parse(hecURL)
+	if err != nil {
+		return nil, fmt.Errorf("invalid splunk hec url: %w", err)
+	}
+	if (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return nil, fmt.Errorf("invalid splunk hec url: %q", hecURL)
+	}
+	if token == "" {
+		return nil, errors.New("missing splunk hec token")
+	}
+
+	if opts.BatchSize <= 0 {
+		opts.BatchSize = defaultSplunkBatchSize
+	}
+	if opts.FlushInterval <= 0 {
+		opts.FlushInterval = defaultSplunkFlushInterval
+	}
+	client := opts.Client
+	ownsClient := client == nil
+	if client == nil {
+		client = &http.Client{Timeout: defaultSplunkTimeout}
+		if opts.InsecureSkipVerify {
+			client.Transport = &http.Transport{
+				TLSClientConfig: &tls.Config{InsecureSkipVerify: true},
+			}
+		}
+	}
+
+	return &splunkCore{
+		LevelEnabler: level,
+		enc:          encoder,
+		sink: &splunkSink{
+			url:           u.String(),
+			token:         token,
+			index:         index,
+			source:        source,
+			client:        client,
+			batchSize:     opts.BatchSize,
+			flushInterval: opts.FlushInterval,
+			ownsClient:    ownsClient,
+		},
+	}, nil
+}
+
+// splunkCore 发送到Splunk HEC的日志核心，通过With派生的核心共享同一个发送缓存
+type splunkCore struct {
+	zapcore.LevelEnabler
+	enc  zapcore.Encoder
+	sink *splunkSink
+}
+
+// With 实现zapcore.Core接口
+func (c *splunkCore) With(fields []zapcore.Field) zapcore.Core {
+	enc := c.enc.Clone()
+	for _, field := range fields {
+		field.AddTo(enc)
+	}
+	return &splunkCore{LevelEnabler: c.LevelEnabler, enc: enc, sink: c.sink}
+}
+
+// Check 实现zapcore.Core接口
+func (c *splunkCore) Check(ent zapcore.Entry, ce *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	if c.Enabled(ent.Level) {
+		return ce.AddCore(ent, c)
+	}
+	return ce
+}
+
+// Write 实现zapcore.Core接口
+func (c *splunkCore) Write(ent zapcore.Entry, fields []zapcore.Field) error {
+	buf, err := c.enc.EncodeEntry(ent, fields)
+	if err != nil {
+		return err
+	}
+	event := bytes.TrimSpace(buf.Bytes())
+	data, err := json.Marshal(splunkEvent{
+		Time:   float64(ent.Time.UnixNano()) / float64(time.Second),
+		Source: c.sink.source,
+		Index:  c.sink.index,
+		Event:  json.RawMessage(event),
+	})
+	buf.Free()
+	if err != nil {
+		return fmt.Errorf("failed to marshal splunk event: %w", err)
+	}
+	return c.sink.add(data)
+}
+
+// Sync 实现zapcore.Core接口，立即发送缓存的事件
+func (c *splunkCore) Sync() error {
+	return c.sink.flush()
+}
+
+// Close 实现io.Closer接口，停止定时发送并发送缓存的事件，之后的写入返回错误
+// 通过With派生的核心共享同一个发送缓存，关闭后均不可再写入
+func (c *splunkCore) Close() error {
+	return c.sink.close()
+}
+
+// splunkEvent Splunk HEC的JSON事件格式
+type splunkEvent struct {
+	Time   float64         `json:"time"`
+	Source string          `json:"source,omitempty"`
+	Index  string          `json:"index,omitempty"`
+	Event  json.RawMessage `json:"event"`
+}
+
+// splunkSink Splunk HEC事件发送缓存
+type splunkSink struct {
+	url           string
+	token         string
+	index         string
+	source        string
+	client        *http.Client
+	batchSize     int
+	flushInterval time.Duration
+	// ownsClient HTTP客户端由本包创建，关闭时释放其空闲连接
+	ownsClient bool
+
+	// sendMu 保证批次按顺序发送
+	sendMu sync.Mutex
+	mu     sync.Mutex
+	events [][]byte
+	// timer 缓存非空时等待发送的定时器
+	timer *time.Timer
+	// closed 是否已关闭
+	closed bool
+}
+
+// add 缓存事件，达到批次大小时立即发送
+func (s *splunkSink) add(event []byte) error {
+	s.mu.Lock()
+	if s.closed {
+		s.mu.Unlock()
+		return errors.New("splunk core is closed")
+	}
+	s.events = append(s.events, event)
+	n := len(s.events)
+	if n == 1 {
+		s.timer = time.AfterFunc(s.flushInterval, s.flushInBackground)
+	}
+	s.mu.Unlock()
+
+	if n >= s.batchSize {
+		return s.flush()
+	}
+	return nil
+}
+
+// flushInBackground 定时发送缓存的事件，错误输出到标准错误
+func (s *splunkSink) flushInBackground() {
+	if err := s.flush(); err != nil {
+		fmt.Fprintf(os.Stderr, "zaploggerfilter: failed to flush splunk events: %v\n", err)
+	}
+}
+
+// flush 发送缓存的事件，发送失败的批次会被丢弃
+func (s *splunkSink) flush() error {
+	s.sendMu.Lock()
+	defer s.sendMu.Unlock()
+
+	s.mu.Lock()
+	events := s.events
+	s.events = nil
+	if s.timer != nil {
+		s.timer.Stop()
+		s.timer = nil
+	}
+	s.mu.Unlock()
+
+	if len(events) == 0 {
+		return nil
+	}
+	return s.send(bytes.Join(events, []byte("\n")))
+}
+
+// close 停止定时器后发送剩余的事件，重复调用不做任何操作
+func (s *splunkSink) close() error {
+	s.mu.Lock()
+	if s.closed {
+		s.mu.Unlock()
+		return nil
+	}
+	s.closed = true
+	if s.timer != nil {
+		s.timer.Stop()
+		s.timer = nil
+	}
+	s.mu.Unlock()
+
+	err := s.flush()
+	if s.ownsClient {
+		s.client.CloseIdleConnections()
+	}
+	return err
+}
+
+// send 将一批事件发送到HEC接口
+func (s *splunkSink) send(body []byte) error {
+	req, err := http.NewRequest(http.MethodPost, s.url, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("failed to create splunk request: %w", err)
+	}
+	req.Header.Set("Authorization", "Splunk "+s.token)
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to send splunk events: %w", err)
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(io.Discard, resp.Body)
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("failed to send splunk events: unexpected status %s", resp.Status)
+	}
+	return nil
+}
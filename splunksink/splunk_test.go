@@ -0,0 +1,110 @@
+package splunksink
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	zaploggerfilter "github.com/november4bin/zap-logger-filter"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+func TestCoreWithFiltersFields(t *testing.T) {
+	core := &splunkCore{
+		LevelEnabler: zapcore.DebugLevel,
+		enc: &zaploggerfilter.SensitiveDataEncoder{
+			Encoder: zapcore.NewJSONEncoder(zapcore.EncoderConfig{MessageKey: "msg"}),
+			Filter:  zaploggerfilter.NewSensitiveDataFilter([]string{"password"}),
+		},
+	}
+
+	derived := core.With([]zapcore.Field{zap.String("password", "hunter2")}).(*splunkCore)
+	out, err := derived.enc.EncodeEntry(zapcore.Entry{Message: "login"}, nil)
+	if err != nil {
+		t.Fatalf("EncodeEntry() error = %v", err)
+	}
+	if strings.Contains(out.String(), "hunter2") {
+		t.Errorf("With field not masked: %s", out.String())
+	}
+}
+
+func TestCoreCloseFlushesEvents(t *testing.T) {
+	var mu sync.Mutex
+	var requests []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		mu.Lock()
+		requests = append(requests, string(body))
+		mu.Unlock()
+	}))
+	defer server.Close()
+
+	// 发送间隔足够长，只有Close会发送缓存的事件
+	core, err := NewCoreWithOptions(server.URL, "token", "", "", zapcore.InfoLevel, Options{FlushInterval: time.Hour})
+	if err != nil {
+		t.Fatalf("NewCoreWithOptions() error = %v", err)
+	}
+	if err := core.Write(zapcore.Entry{Message: "pending", Time: time.Now()}, nil); err != nil {
+		t.Fatalf("Write() error = %v", err)
+	}
+
+	closer := core.(io.Closer)
+	if err := closer.Close(); err != nil {
+		t.Fatalf("Close() error = %v", err)
+	}
+	mu.Lock()
+	if len(requests) != 1 || !strings.Contains(requests[0], "pending") {
+		t.Errorf("requests after Close = %q, want the pending event", requests)
+	}
+	mu.Unlock()
+
+	if err := core.Write(zapcore.Entry{Message: "late", Time: time.Now()}, nil); err == nil {
+		t.Error("Write() after Close error = nil, want error")
+	}
+	if timer := core.(*splunkCore).sink.timer; timer != nil {
+		t.Error("flush timer still set after Close")
+	}
+	if err := closer.Close(); err != nil {
+		t.Errorf("second Close() error = %v", err)
+	}
+}
+
+func TestResetClosesConfiguredCore(t *testing.T) {
+	events := make(chan string, 1)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		events <- string(body)
+	}))
+	defer server.Close()
+
+	err := zaploggerfilter.Reinit([]zaploggerfilter.Config{{
+		Type:            zaploggerfilter.Splunk,
+		Name:            "splunk",
+		Level:           "info",
+		SensitiveFilter: true,
+		SensitiveFields: []string{"password"},
+		HECUrl:          server.URL,
+		HECToken:        "token",
+	}})
+	if err != nil {
+		t.Fatalf("Reinit() error = %v", err)
+	}
+	zaploggerfilter.InfoTo("splunk", "login", zap.String("password", "hunter2"))
+
+	if err := zaploggerfilter.Reset(); err != nil {
+		t.Fatalf("Reset() error = %v", err)
+	}
+	select {
+	case got := <-events:
+		if !strings.Contains(got, "login") || strings.Contains(got, "hunter2") {
+			t.Errorf("event = %q, want masked login event", got)
+		}
+	default:
+		t.Error("Reset did not flush the pending splunk event")
+	}
+}
@@ -0,0 +1,137 @@
+package zaploggerfilter
+
+import (
+	"sync/atomic"
+	"time"
+
+	"go.uber.org/zap/zapcore"
+)
+
+// LoggerStats 命名日志记录器的统计信息
+type LoggerStats struct {
+	// LastLogTime 最后一次写入日志的时间，从未写入时为零值
+	LastLogTime time.Time
+	// TotalEntries 写入的日志条目总数
+	TotalEntries int64
+	// EntriesByLevel 各级别写入的日志条目数，键为级别名称
+	EntriesByLevel map[string]int64
+	// WriteErrors 写入失败的次数
+	WriteErrors int64
+}
+
+// loggerCounters 命名日志记录器的统计计数器，派生的日志记录器共享同一组计数器
+type loggerCounters struct {
+	// created 创建时间（Unix纳秒）
+	created int64
+	// lastWrite 最后一次写入的时间（Unix纳秒），从未写入时为0
+	lastWrite  atomic.Int64
+	total      atomic.Int64
+	byLevel    [zapcore.FatalLevel - zapcore.DebugLevel + 1]atomic.Int64
+	errorCount atomic.Int64
+}
+
+// newLoggerCounters 创建统计计数器
+func newLoggerCounters() *loggerCounters {
+	return &loggerCounters{created: time.Now().UnixNano()}
+}
+
+// lastActive 最后一次写入的时间，从未写入时为创建时间（Unix纳秒）
+func (c *loggerCounters) lastActive() int64 {
+	if last := c.lastWrite.Load(); last != 0 {
+		return last
+	}
+	return c.created
+}
+
+// record 记录一次写入
+func (c *loggerCounters) record(lvl zapcore.Level, err error) {
+	c.lastWrite.Store(time.Now().UnixNano())
+	c.total.Add(1)
+	if lvl >= zapcore.DebugLevel && lvl <= zapcore.FatalLevel {
+		c.byLevel[lvl-zapcore.DebugLevel].Add(1)
+	}
+	if err != nil {
+		c.errorCount.Add(1)
+	}
+}
+
+// snapshot 获取统计信息的副本
+func (c *loggerCounters) snapshot() *LoggerStats {
+	stats := &LoggerStats{
+		TotalEntries:   c.total.Load(),
+		EntriesByLevel: make(map[string]int64, len(c.byLevel)),
+		WriteErrors:    c.errorCount.Load(),
+	}
+	if last := c.lastWrite.Load(); last != 0 {
+		stats.LastLogTime = time.Unix(0, last)
+	}
+	for i := range c.byLevel {
+		if n := c.byLevel[i].Load(); n > 0 {
+			stats.EntriesByLevel[(zapcore.DebugLevel + zapcore.Level(i)).String()] = n
+		}
+	}
+	return stats
+}
+
+// statsCore 统计写入情况的日志核心，直接包装写入最终输出的日志核心
+// 冷却等限制写入的日志核心位于其外层，被丢弃的日志条目不会被统计
+type statsCore struct {
+	zapcore.Core
+	counters *loggerCounters
+	// checkInner 是否需要先经内部日志核心的Check检查，用于可能有自己的Check逻辑（如采样）的自定义日志核心
+	checkInner bool
+}
+
+// newStatsCore 包装日志核心以统计写入情况，counters为nil时返回原日志核心
+func newStatsCore(core zapcore.Core, counters *loggerCounters, checkInner bool) zapcore.Core {
+	if counters == nil {
+		return core
+	}
+	return &statsCore{Core: core, counters: counters, checkInner: checkInner}
+}
+
+// With 实现zapcore.Core接口
+func (c *statsCore) With(fields []zapcore.Field) zapcore.Core {
+	return &statsCore{Core: c.Core.With(fields), counters: c.counters, checkInner: c.checkInner}
+}
+
+// Check 实现zapcore.Core接口
+func (c *statsCore) Check(ent zapcore.Entry, ce *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	if !c.Enabled(ent.Level) {
+		return ce
+	}
+	if c.checkInner && c.Core.Check(ent, nil) == nil {
+		return ce
+	}
+	return ce.AddCore(ent, c)
+}
+
+// Write 实现zapcore.Core接口
+func (c *statsCore) Write(ent zapcore.Entry, fields []zapcore.Field) error {
+	err := c.Core.Write(ent, fields)
+	c.counters.record(ent.Level, err)
+	return err
+}
+
+// GetLoggerStats 获取命名日志记录器的统计信息，目标不存在时返回false
+// 派生的日志记录器（如通过WithFields创建的）与父日志记录器共享统计信息
+func GetLoggerStats(name string) (*LoggerStats, bool) {
+	nl, ok := loadLogger(name)
+	if !ok || nl.counters == nil {
+		return nil, false
+	}
+	return nl.counters.snapshot(), true
+}
+
+// AllLoggerStats 获取所有命名日志记录器的统计信息，键为日志记录器名称
+// 派生的日志记录器与父日志记录器共享统计信息，不单独返回
+func AllLoggerStats() map[string]*LoggerStats {
+	all := make(map[string]*LoggerStats)
+	l.Range(func(k, v interface{}) bool {
+		if nl := v.(*namedLogger); !nl.derived && nl.counters != nil {
+			all[k.(string)] = nl.counters.snapshot()
+		}
+		return true
+	})
+	return all
+}
@@ -0,0 +1,83 @@
+package zaploggerfilter
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+
+	"go.uber.org/zap/zapcore"
+)
+
+// filterCommand 双向输出中读取的过滤器更新命令
+type filterCommand struct {
+	// AddField 添加敏感字段
+	AddField string `json:"add_field"`
+	// RemoveField 移除敏感字段
+	RemoveField string `json:"remove_field"`
+}
+
+// NewBidirectionalCore 创建使用双向连接（如WebSocket连接）的日志记录器核心
+// JSON格式的日志条目经过filter过滤后写入rw；后台goroutine从rw读取JSON格式的过滤器更新命令
+// （如 {"add_field":"password"} 或 {"remove_field":"password"}）并应用到filter，
+// 用于实时调整掩码字段的日志查看器。读取结束（如连接关闭）时后台goroutine退出
+// 通过With添加的字段同样由filter过滤
+func NewBidirectionalCore(rw io.ReadWriter, level zapcore.Level, filter *SensitiveDataFilter) (zapcore.Core, error) {
+	if rw == nil {
+		return nil, errors.New("nil read writer")
+	}
+	if filter == nil {
+		return nil, errors.New("nil sensitive data filter")
+	}
+
+	go readFilterCommands(rw, filter)
+	return zapcore.NewCore(NewJSONEncoderWithFilter(encoderConfig, filter), zapcore.Lock(zapcore.AddSync(rw)), level), nil
+}
+
+// readFilterCommands 读取并应用过滤器更新命令，直到读取结束
+// 无效的命令输出到标准错误后跳过
+func readFilterCommands(r io.Reader, filter *SensitiveDataFilter) {
+	dec := json.NewDecoder(r)
+	for {
+		var cmd filterCommand
+		err := dec.Decode(&cmd)
+		if err == nil {
+			if err = applyFilterCommand(filter, cmd); err != nil {
+				fmt.Fprintf(os.Stderr, "zaploggerfilter: %v\n", err)
+			}
+			continue
+		}
+
+		var syntaxErr *json.SyntaxError
+		var typeErr *json.UnmarshalTypeError
+		switch {
+		case errors.As(err, &typeErr):
+			// 类型错误时解码器已读取完整的值，可以继续读取下一条命令
+			fmt.Fprintf(os.Stderr, "zaploggerfilter: invalid filter command: %v\n", err)
+		case errors.As(err, &syntaxErr):
+			// 语法错误后无法继续解析
+			fmt.Fprintf(os.Stderr, "zaploggerfilter: invalid filter command: %v\n", err)
+			return
+		default:
+			if !errors.Is(err, io.EOF) {
+				fmt.Fprintf(os.Stderr, "zaploggerfilter: failed to read filter command: %v\n", err)
+			}
+			return
+		}
+	}
+}
+
+// applyFilterCommand 将过滤器更新命令应用到过滤器
+func applyFilterCommand(filter *SensitiveDataFilter, cmd filterCommand) error {
+	if cmd.AddField == "" && cmd.RemoveField == "" {
+		return errors.New("empty filter command")
+	}
+	if cmd.AddField != "" {
+		filter.AddField(cmd.AddField)
+	}
+	if cmd.RemoveField != "" {
+		filter.RemoveField(cmd.RemoveField)
+	}
+	return nil
+}
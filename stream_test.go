@@ -0,0 +1,35 @@
+package zaploggerfilter
+
+import (
+	"strings"
+	"testing"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// commandConn 从commands读取过滤器更新命令、将日志写入out的双向连接
+type commandConn struct {
+	*strings.Reader
+	out syncBuffer
+}
+
+// Write 实现io.Writer接口
+func (c *commandConn) Write(p []byte) (int, error) {
+	return c.out.Write(p)
+}
+
+func TestBidirectionalCoreWithFields(t *testing.T) {
+	conn := &commandConn{Reader: strings.NewReader("")}
+	core, err := NewBidirectionalCore(conn, zapcore.InfoLevel, NewSensitiveDataFilter([]string{"password"}))
+	if err != nil {
+		t.Fatalf("NewBidirectionalCore() error = %v", err)
+	}
+
+	zap.New(core).With(zap.String("password", "hunter2")).Info("login", zap.String("token", "abc"))
+
+	out := conn.out.String()
+	if strings.Contains(out, "hunter2") || !strings.Contains(out, `"password":"***"`) {
+		t.Errorf("With field not masked: %s", out)
+	}
+}
@@ -0,0 +1,97 @@
+package zaploggerfilter
+
+import (
+	"errors"
+	"runtime"
+	"strconv"
+	"strings"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// tracedErrorMaxFrames 记录堆栈的最大帧数
+const tracedErrorMaxFrames = 64
+
+// tracedErrorStackSuffix 堆栈字段的键名后缀
+const tracedErrorStackSuffix = "_stacktrace"
+
+// TracedError 在创建时记录堆栈的错误
+// 通过SensitiveDataEncoder记录时，会追加 key+"_stacktrace" 堆栈字段以及创建时附加的字段
+type TracedError struct {
+	err    error
+	fields []zapcore.Field
+	pcs    []uintptr
+}
+
+// NewTracedError 包装错误并记录当前的调用堆栈，err为nil时返回nil
+// fields: 记录该错误时一并输出的字段，同样会经过敏感数据过滤器处理
+func NewTracedError(err error, fields ...zapcore.Field) error {
+	if err == nil {
+		return nil
+	}
+
+	pcs := make([]uintptr, tracedErrorMaxFrames)
+	// 跳过runtime.Callers和NewTracedError
+	n := runtime.Callers(2, pcs)
+	return &TracedError{err: err, fields: fields, pcs: pcs[:n]}
+}
+
+// Error 实现error接口
+func (e *TracedError) Error() string {
+	return e.err.Error()
+}
+
+// Unwrap 返回被包装的错误
+func (e *TracedError) Unwrap() error {
+	return e.err
+}
+
+// Fields 创建错误时附加的字段
+func (e *TracedError) Fields() []zapcore.Field {
+	return e.fields
+}
+
+// StackTrace 创建错误时的调用堆栈，格式与zap的stacktrace字段一致
+func (e *TracedError) StackTrace() string {
+	var b strings.Builder
+	frames := runtime.CallersFrames(e.pcs)
+	for {
+		frame, more := frames.Next()
+		if b.Len() > 0 {
+			b.WriteByte('\n')
+		}
+		b.WriteString(frame.Function)
+		b.WriteString("\n\t")
+		b.WriteString(frame.File)
+		b.WriteByte(':')
+		b.WriteString(strconv.Itoa(frame.Line))
+		if !more {
+			break
+		}
+	}
+	return b.String()
+}
+
+// tracedErrorFields 获取错误字段中TracedError的堆栈字段和附加字段
+// mask为true时附加字段会经过过滤器处理（审计模式下保留原始值）；字段不是TracedError时返回nil
+func (f *SensitiveDataFilter) tracedErrorFields(field zapcore.Field, mask bool) []zapcore.Field {
+	if field.Type != zapcore.ErrorType {
+		return nil
+	}
+	err, ok := field.Interface.(error)
+	if !ok {
+		return nil
+	}
+	var traced *TracedError
+	if !errors.As(err, &traced) {
+		return nil
+	}
+
+	fields := make([]zapcore.Field, 0, len(traced.fields)+1)
+	fields = append(fields, zap.String(field.Key+tracedErrorStackSuffix, traced.StackTrace()))
+	if !mask {
+		return append(fields, traced.fields...)
+	}
+	return append(fields, f.filterFields(traced.fields)...)
+}
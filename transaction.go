@@ -0,0 +1,141 @@
+package zaploggerfilter
+
+import (
+	"errors"
+	"fmt"
+	"runtime"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// ErrTransactionDone 日志事务已经提交或回滚
+var ErrTransactionDone = errors.New("log transaction already finished")
+
+// targetLocks 目标日志记录器的写入锁，保证批量写入的条目不会与其他批量写入交错
+var targetLocks sync.Map
+
+// lockTarget 获取目标日志记录器的写入锁
+// 返回: 释放锁的函数
+func lockTarget(target string) func() {
+	v, _ := targetLocks.LoadOrStore(target, &sync.Mutex{})
+	mu := v.(*sync.Mutex)
+	mu.Lock()
+	return mu.Unlock
+}
+
+// writeEntry 直接通过日志记录器的核心写入日志条目，保留条目中的时间和调用者信息
+func writeEntry(lg *zap.Logger, ent zapcore.Entry, fields []zapcore.Field) {
+	if ce := lg.Core().Check(ent, nil); ce != nil {
+		ce.Write(fields...)
+	}
+}
+
+// LogTransaction 日志事务，缓存日志条目直到提交或回滚
+type LogTransaction struct {
+	target  string
+	logger  *zap.Logger
+	mu      sync.Mutex
+	entries []bufferedEntry
+	done    bool
+}
+
+// bufferedEntry 缓存的日志条目
+type bufferedEntry struct {
+	ent    zapcore.Entry
+	fields []zapcore.Field
+}
+
+// BeginLogTransaction 开始一个向指定目标记录日志的事务
+func BeginLogTransaction(target string) (*LogTransaction, error) {
+	lg, ok := GetTargetLogger(target)
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrLoggerNotFound, target)
+	}
+	return &LogTransaction{target: target, logger: lg}, nil
+}
+
+// CommitLogTransaction 提交事务，将缓存的日志条目一次性写入目标日志记录器
+// 同一目标的多个事务提交会串行执行，条目不会交错
+func CommitLogTransaction(tx *LogTransaction) error {
+	tx.mu.Lock()
+	defer tx.mu.Unlock()
+
+	if tx.done {
+		return ErrTransactionDone
+	}
+	tx.done = true
+
+	unlock := lockTarget(tx.target)
+	defer unlock()
+
+	for _, entry := range tx.entries {
+		writeEntry(tx.logger, entry.ent, entry.fields)
+	}
+	tx.entries = nil
+	return nil
+}
+
+// RollbackLogTransaction 回滚事务，丢弃缓存的日志条目
+func RollbackLogTransaction(tx *LogTransaction) error {
+	tx.mu.Lock()
+	defer tx.mu.Unlock()
+
+	if tx.done {
+		return ErrTransactionDone
+	}
+	tx.done = true
+	tx.entries = nil
+	return nil
+}
+
+// Log 在事务中记录指定级别的日志
+func (tx *LogTransaction) Log(lvl zapcore.Level, msg string, fields ...zapcore.Field) {
+	tx.add(lvl, msg, fields)
+}
+
+// Debug 在事务中记录调试级别的日志
+func (tx *LogTransaction) Debug(msg string, fields ...zapcore.Field) {
+	tx.add(zapcore.DebugLevel, msg, fields)
+}
+
+// Info 在事务中记录信息级别的日志
+func (tx *LogTransaction) Info(msg string, fields ...zapcore.Field) {
+	tx.add(zapcore.InfoLevel, msg, fields)
+}
+
+// Warn 在事务中记录警告级别的日志
+func (tx *LogTransaction) Warn(msg string, fields ...zapcore.Field) {
+	tx.add(zapcore.WarnLevel, msg, fields)
+}
+
+// Error 在事务中记录错误级别的日志
+func (tx *LogTransaction) Error(msg string, fields ...zapcore.Field) {
+	tx.add(zapcore.ErrorLevel, msg, fields)
+}
+
+// add 缓存日志条目，记录时的时间和调用者信息会被保留
+// 只能由Log、Debug等方法直接调用
+func (tx *LogTransaction) add(lvl zapcore.Level, msg string, fields []zapcore.Field) {
+	if !tx.logger.Core().Enabled(lvl) {
+		return
+	}
+
+	ent := zapcore.Entry{
+		LoggerName: tx.logger.Name(),
+		Time:       time.Now(),
+		Level:      lvl,
+		Message:    msg,
+		Caller:     zapcore.NewEntryCaller(runtime.Caller(2)),
+	}
+
+	tx.mu.Lock()
+	defer tx.mu.Unlock()
+
+	if tx.done {
+		return
+	}
+	tx.entries = append(tx.entries, bufferedEntry{ent: ent, fields: fields})
+}
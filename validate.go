@@ -0,0 +1,178 @@
+package zaploggerfilter
+
+import (
+	"errors"
+	"fmt"
+	"maps"
+	"path/filepath"
+	"slices"
+)
+
+// Validate 校验日志记录器配置，不会创建任何日志记录器
+// 返回: 每个无效配置对应一个错误，同一配置的多个问题会合并到该错误中；全部有效时返回nil
+func Validate(cfg []Config) []error {
+	errs, _ := ValidateWithWarnings(cfg)
+	return errs
+}
+
+// ValidateWithWarnings 校验日志记录器配置，同时检查合法但可能存在问题的配置组合
+// 返回: 与Validate相同的错误，以及每个存在问题的配置对应的警告；警告不影响日志记录器的创建
+func ValidateWithWarnings(cfg []Config) (errs []error, warnings []error) {
+	return validateErrors(cfg), validateWarnings(cfg)
+}
+
+// validateErrors 校验所有配置，返回每个无效配置对应的错误
+func validateErrors(cfg []Config) []error {
+	var errs []error
+	names := make(map[string]bool, len(cfg))
+	conflicts := pathConflicts(cfg)
+	for i, c := range cfg {
+		problems := append(validateConfig(c), conflicts[i]...)
+		if c.Name != "" {
+			if names[c.Name] {
+				problems = append(problems, fmt.Errorf("duplicate name: %q", c.Name))
+			}
+			names[c.Name] = true
+		}
+		if len(problems) > 0 {
+			errs = append(errs, fmt.Errorf("config %d (%q): %w", i, c.Name, errors.Join(problems...)))
+		}
+	}
+	return errs
+}
+
+// pathConflicts 检查使用相同文件路径的文件日志记录器配置，多个日志记录器并发写入同一文件会导致日志交错或损坏
+// 返回: 配置索引到路径冲突的映射，冲突记录在后出现的配置上，并指明先使用该路径的配置
+func pathConflicts(cfg []Config) map[int][]error {
+	var conflicts map[int][]error
+	owners := make(map[string]int)
+	for i, c := range cfg {
+		if (c.Type != File && c.Type != LogfmtFile) || c.WriteSyncer != nil {
+			continue
+		}
+		for _, path := range filePaths(c) {
+			key := filepath.Clean(path)
+			j, ok := owners[key]
+			if !ok {
+				owners[key] = i
+				continue
+			}
+			if conflicts == nil {
+				conflicts = make(map[int][]error)
+			}
+			conflicts[i] = append(conflicts[i], fmt.Errorf("path %q is already used by config %d (%q)", path, j, cfg[j].Name))
+		}
+	}
+	return conflicts
+}
+
+// validateConfig 校验单个配置
+func validateConfig(c Config) []error {
+	var problems []error
+	if c.Name == "" {
+		problems = append(problems, errors.New("empty name"))
+	}
+	if _, err := parseLoggerLevel(c.Level); err != nil {
+		problems = append(problems, err)
+	}
+	if _, err := getTimeEncoder(c.TimeFormat); err != nil {
+		problems = append(problems, err)
+	}
+
+	switch c.Type {
+	case Console, LogfmtConsole:
+	case File, LogfmtFile:
+		if c.Path == "" && len(c.Paths) == 0 && c.WriteSyncer == nil {
+			problems = append(problems, errors.New("missing path for file logger"))
+		}
+	case Splunk:
+		if _, ok := lookupEncoderCoreBuilder(c.Type); !ok {
+			problems = append(problems, errUnregisteredSink(c.Type))
+		}
+		if c.HECUrl == "" {
+			problems = append(problems, errors.New("missing hec url for splunk logger"))
+		}
+		if c.HECToken == "" {
+			problems = append(problems, errors.New("missing hec token for splunk logger"))
+		}
+	case NATS:
+		if _, ok := lookupEncoderCoreBuilder(c.Type); !ok {
+			problems = append(problems, errUnregisteredSink(c.Type))
+		}
+		if len(c.Servers) == 0 {
+			problems = append(problems, errors.New("missing servers for nats logger"))
+		}
+		if c.Subject == "" {
+			problems = append(problems, errors.New("missing subject for nats logger"))
+		}
+	default:
+		if !isRegistered(c.Type) {
+			problems = append(problems, fmt.Errorf("unknown zap core type: %q", c.Type))
+		}
+	}
+
+	fields := make(map[string]bool, len(c.SensitiveFields))
+	for _, field := range c.SensitiveFields {
+		name := normalizeFieldName(field)
+		if name == "" {
+			problems = append(problems, errors.New("empty sensitive field"))
+			continue
+		}
+		if fields[name] {
+			problems = append(problems, fmt.Errorf("duplicate sensitive field: %q", field))
+		}
+		fields[name] = true
+	}
+
+	if c.MaxSize < 0 {
+		problems = append(problems, fmt.Errorf("negative max size: %d", c.MaxSize))
+	}
+	if c.MaxSizeBytes < 0 {
+		problems = append(problems, fmt.Errorf("negative max size bytes: %d", c.MaxSizeBytes))
+	}
+	if c.MaxAge < 0 {
+		problems = append(problems, fmt.Errorf("negative max age: %d", c.MaxAge))
+	}
+	if c.MaxBackups < 0 {
+		problems = append(problems, fmt.Errorf("negative max backups: %d", c.MaxBackups))
+	}
+	for _, name := range slices.Sorted(maps.Keys(c.MinIntervalMs)) {
+		ms := c.MinIntervalMs[name]
+		if _, err := parseLoggerLevel(name); err != nil {
+			problems = append(problems, fmt.Errorf("invalid min interval level: %w", err))
+		}
+		if ms < 0 {
+			problems = append(problems, fmt.Errorf("negative min interval for level %q: %d", name, ms))
+		}
+	}
+	if c.NATSReconnectBufSize < 0 {
+		problems = append(problems, fmt.Errorf("negative nats reconnect buffer size: %d", c.NATSReconnectBufSize))
+	}
+	if c.IdleTimeout < 0 {
+		problems = append(problems, fmt.Errorf("negative idle timeout: %s", c.IdleTimeout))
+	}
+	return problems
+}
+
+// validateWarnings 检查所有配置，返回每个存在问题的配置对应的警告
+func validateWarnings(cfg []Config) []error {
+	var warnings []error
+	for i, c := range cfg {
+		if problems := configWarnings(c); len(problems) > 0 {
+			warnings = append(warnings, fmt.Errorf("config %d (%q): %w", i, c.Name, errors.Join(problems...)))
+		}
+	}
+	return warnings
+}
+
+// configWarnings 检查单个配置中合法但可能存在问题的配置组合
+func configWarnings(c Config) []error {
+	var problems []error
+	if c.SensitiveFilter && len(c.SensitiveFields) == 0 {
+		problems = append(problems, errors.New("sensitive filter is enabled without sensitive fields"))
+	}
+	if (c.Type == File || c.Type == LogfmtFile) && c.WriteSyncer == nil && c.MaxSize == 0 && c.MaxSizeBytes == 0 {
+		problems = append(problems, errors.New("max size is not set, files rotate at the default 100 MB"))
+	}
+	return problems
+}
@@ -0,0 +1,44 @@
+// Package zaploggerfiltertest 提供在测试中使用zaploggerfilter的辅助函数
+package zaploggerfiltertest
+
+import (
+	"sync"
+	"testing"
+
+	zaploggerfilter "github.com/november4bin/zap-logger-filter"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zaptest"
+)
+
+// NewLoggerFactory 创建用于测试的日志记录器工厂，不会修改zaploggerfilter包级别的全局状态
+// t不为nil时日志通过zaptest输出到t.Log，否则丢弃所有日志
+// 通过SetGlobal设置的日志记录器会作为之后所有GetLogger的基础日志记录器
+func NewLoggerFactory(t testing.TB) zaploggerfilter.LoggerFactory {
+	base := zap.NewNop()
+	if t != nil {
+		base = zaptest.NewLogger(t)
+	}
+	return &loggerFactory{base: base}
+}
+
+// loggerFactory 用于测试的日志记录器工厂
+type loggerFactory struct {
+	mu   sync.RWMutex
+	base *zap.Logger
+}
+
+// GetLogger 实现zaploggerfilter.LoggerFactory接口
+func (f *loggerFactory) GetLogger(name string) *zap.Logger {
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+
+	return f.base.Named(name)
+}
+
+// SetGlobal 实现zaploggerfilter.LoggerFactory接口
+func (f *loggerFactory) SetGlobal(lg *zap.Logger) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.base = lg
+}
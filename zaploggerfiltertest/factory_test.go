@@ -0,0 +1,25 @@
+package zaploggerfiltertest
+
+import (
+	"testing"
+
+	zaploggerfilter "github.com/november4bin/zap-logger-filter"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zaptest/observer"
+)
+
+func TestLoggerFactorySetGlobal(t *testing.T) {
+	factory := NewLoggerFactory(t)
+	core, logs := observer.New(zap.InfoLevel)
+	factory.SetGlobal(zap.New(core))
+
+	factory.GetLogger("handler").Info("handled")
+
+	// 不会修改包级别的全局日志记录器
+	zaploggerfilter.GetGlobalLogger().Info("global")
+
+	entries := logs.All()
+	if len(entries) != 1 || entries[0].LoggerName != "handler" {
+		t.Errorf("entries = %+v, want one entry from the handler logger", entries)
+	}
+}